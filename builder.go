@@ -0,0 +1,83 @@
+package pushy
+
+// NotificationBuilder builds a SendNotificationRequest step by step,
+// Build validates the request so mistakes are caught before it's sent to pushy
+//  request, err := pushy.NewNotificationBuilder("DEVICE_ID").Data(`{"message":"hi"}`).Build()
+type NotificationBuilder struct {
+	request SendNotificationRequest
+	web     *WebNotification
+}
+
+// NewNotificationBuilder starts a new notification for devices (or topics) in to
+func NewNotificationBuilder(to ...string) *NotificationBuilder {
+	return &NotificationBuilder{
+		request: SendNotificationRequest{To: to},
+	}
+}
+
+// To adds more recipients
+func (b *NotificationBuilder) To(to ...string) *NotificationBuilder {
+	b.request.To = append(b.request.To, to...)
+	return b
+}
+
+// Data sets payload which is delivered to the app
+func (b *NotificationBuilder) Data(data string) *NotificationBuilder {
+	b.request.Data = data
+	return b
+}
+
+// TimeToLive sets for how long (in seconds) pushy keeps trying to deliver the notification
+func (b *NotificationBuilder) TimeToLive(seconds int) *NotificationBuilder {
+	b.request.TimeToLive = seconds
+	return b
+}
+
+// IOS sets notification shown on iOS devices
+func (b *NotificationBuilder) IOS(notification IOSNotification) *NotificationBuilder {
+	b.request.IOSNotification = notification
+	return b
+}
+
+// IOSMutableContent allows notification service extension to modify the notification
+func (b *NotificationBuilder) IOSMutableContent(mutable bool) *NotificationBuilder {
+	b.request.IOSMutableContent = mutable
+	return b
+}
+
+// IOSContentAvailable wakes the app up in background when notification is received
+func (b *NotificationBuilder) IOSContentAvailable(available bool) *NotificationBuilder {
+	b.request.IOSContentAvailable = available
+	return b
+}
+
+// Web sets notification shown by browsers, Build merges it into data
+func (b *NotificationBuilder) Web(notification WebNotification) *NotificationBuilder {
+	b.web = &notification
+	return b
+}
+
+// Android sets delivery options for android devices
+func (b *NotificationBuilder) Android(options AndroidOptions) *NotificationBuilder {
+	b.request.AndroidOptions = options
+	return b
+}
+
+// Build returns the request or the first ValidationError found
+func (b *NotificationBuilder) Build() (SendNotificationRequest, error) {
+	request := b.request
+	if b.web != nil {
+		if err := b.web.Validate(); err != nil {
+			return SendNotificationRequest{}, err
+		}
+		data, err := b.web.MergeData(request.Data)
+		if err != nil {
+			return SendNotificationRequest{}, err
+		}
+		request.Data = data
+	}
+	if err := request.Validate(); err != nil {
+		return SendNotificationRequest{}, err
+	}
+	return request, nil
+}
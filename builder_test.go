@@ -0,0 +1,138 @@
+package pushy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationBuilder_Build(t *testing.T) {
+	Assert := assert.New(t)
+	request, err := pushy.NewNotificationBuilder("DEVICE").
+		To("/topics/news").
+		Data(`{"message":"hello"}`).
+		TimeToLive(60).
+		IOS(pushy.IOSNotification{Title: "Hi", Body: "hello"}).
+		IOSMutableContent(true).
+		IOSContentAvailable(true).
+		Web(pushy.WebNotification{Title: "Hi", URL: "https://example.com"}).
+		Build()
+	Assert.Nil(err)
+	Assert.Equal([]string{"DEVICE", "/topics/news"}, request.To)
+	Assert.Equal(`{"message":"hello","title":"Hi","url":"https://example.com"}`, request.Data)
+	Assert.Equal(60, request.TimeToLive)
+	Assert.Equal("Hi", request.IOSNotification.Title)
+	Assert.True(request.IOSMutableContent)
+	Assert.True(request.IOSContentAvailable)
+}
+
+func TestNotificationBuilder_BuildValidates(t *testing.T) {
+	table := []struct {
+		builder *pushy.NotificationBuilder
+		field   string
+	}{
+		{builder: pushy.NewNotificationBuilder(), field: "to"},
+		{builder: pushy.NewNotificationBuilder("DEVICE", ""), field: "to[1]"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").TimeToLive(-1), field: "time_to_live"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Web(pushy.WebNotification{}), field: "data.title"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Web(pushy.WebNotification{Title: "t", URL: "/relative"}), field: "data.url"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Web(pushy.WebNotification{Title: "t", Image: "ftp://example.com/icon.png"}), field: "data.image"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Web(pushy.WebNotification{Title: "t", Image: "https://"}), field: "data.image"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Data(`["list"]`).Web(pushy.WebNotification{Title: "t"}), field: "data"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Android(pushy.AndroidOptions{Priority: "urgent"}), field: "priority"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Android(pushy.AndroidOptions{ChannelID: " news"}), field: "channel_id"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Android(pushy.AndroidOptions{RestrictedPackageName: "app"}), field: "restricted_package_name"},
+		{builder: pushy.NewNotificationBuilder("DEVICE").Android(pushy.AndroidOptions{RestrictedPackageName: "com.1example.app"}), field: "restricted_package_name"},
+	}
+	for _, data := range table {
+		_, err := data.builder.Build()
+		validationErr, ok := err.(pushy.ValidationError)
+		if !assert.True(t, ok, data.field) {
+			continue
+		}
+		assert.Equal(t, data.field, validationErr.Field)
+		assert.Contains(t, validationErr.Error(), data.field)
+	}
+}
+
+func TestWebNotification_JSON(t *testing.T) {
+	Assert := assert.New(t)
+	request, err := pushy.NewNotificationBuilder("BROWSER").
+		Data(`{"order_id":7}`).
+		Web(pushy.WebNotification{
+			Title:   "Sale",
+			Message: "Everything is 50% off",
+			URL:     "https://example.com/sale",
+			Image:   "https://example.com/icon.png",
+		}).
+		Build()
+	Assert.Nil(err)
+	// pushy's web sdk reads title, message, url and image from data
+	Assert.JSONEq(`{
+		"order_id": 7,
+		"title": "Sale",
+		"message": "Everything is 50% off",
+		"url": "https://example.com/sale",
+		"image": "https://example.com/icon.png"
+	}`, request.Data)
+
+	merged, err := pushy.WebNotification{Title: "<b>Sale</b>"}.MergeData("")
+	Assert.Nil(err)
+	Assert.Equal(`{"title":"<b>Sale</b>"}`, merged)
+	merged, err = pushy.WebNotification{Title: "Sale"}.MergeData(`{"title":"old","url":"https://example.com"}`)
+	Assert.Nil(err)
+	Assert.Equal(`{"title":"Sale","url":"https://example.com"}`, merged)
+	_, err = pushy.WebNotification{Title: "Sale"}.MergeData("null")
+	Assert.IsType(pushy.ValidationError{}, err)
+}
+
+func TestAndroidOptions_JSON(t *testing.T) {
+	Assert := assert.New(t)
+	request, err := pushy.NewNotificationBuilder("DEVICE").
+		Data(`{"message":"hello"}`).
+		Android(pushy.AndroidOptions{
+			Priority:              pushy.AndroidPriorityHigh,
+			ChannelID:             "news",
+			CollapseKey:           "breaking",
+			RestrictedPackageName: "com.example.app",
+			DirectBootOK:          true,
+		}).
+		Build()
+	Assert.Nil(err)
+	encoded, err := json.Marshal(request)
+	Assert.Nil(err)
+	// options are parameters of the request itself, like collapse_key in pushy's send api
+	Assert.Equal(`{"to":["DEVICE"],"data":"{\"message\":\"hello\"}","time_to_live":0,"mutable_content":false,"content_available":false,`+
+		`"notification":{"body":"","badge":0,"sound":"","title":"","category":"","loc_key":"","loc_args":null,"title_loc_key":"","title_loc_args":null},`+
+		`"priority":"high","channel_id":"news","collapse_key":"breaking","restricted_package_name":"com.example.app","direct_boot_ok":true}`, string(encoded))
+
+	request, err = pushy.NewNotificationBuilder("DEVICE").Android(pushy.AndroidOptions{CollapseKey: "sale"}).Build()
+	Assert.Nil(err)
+	encoded, err = json.Marshal(request)
+	Assert.Nil(err)
+	Assert.Contains(string(encoded), `"title_loc_args":null},"collapse_key":"sale"}`)
+
+	var decoded pushy.SendNotificationRequest
+	Assert.Nil(json.Unmarshal(encoded, &decoded))
+	Assert.Equal(pushy.AndroidOptions{CollapseKey: "sale"}, decoded.AndroidOptions)
+
+	encoded, err = json.Marshal(pushy.SendNotificationRequest{})
+	Assert.Nil(err)
+	Assert.NotContains(string(encoded), `collapse_key`)
+	Assert.NotContains(string(encoded), `"android"`)
+}
+
+func TestAndroidOptions_ZeroRequest(t *testing.T) {
+	Assert := assert.New(t)
+	// promoted fields can be set on a request without options
+	var request pushy.SendNotificationRequest
+	request.To = []string{"DEVICE"}
+	request.Priority = pushy.AndroidPriorityHigh
+	request.CollapseKey = "sale"
+	Assert.Nil(request.Validate())
+	encoded, err := json.Marshal(request)
+	Assert.Nil(err)
+	Assert.Contains(string(encoded), `"priority":"high","collapse_key":"sale"}`)
+}
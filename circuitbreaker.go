@@ -0,0 +1,228 @@
+package pushy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker without making a request while pushy is considered down
+var ErrCircuitOpen = errors.New("pushy: circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every request with ErrCircuitOpen until cooldown passes
+	CircuitOpen
+	// CircuitHalfOpen lets a few trial requests through to see if pushy has recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker, zero values are replaced with defaults
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures which opens the circuit, defaults to 5
+	FailureThreshold int
+	// Cooldown is how long circuit stays open before trial requests are allowed, defaults to 30s
+	Cooldown time.Duration
+	// HalfOpenRequests is the number of successful trial requests needed to close the circuit, defaults to 1.
+	// it's also the number of trial requests which can run at once
+	HalfOpenRequests int
+	// IsFailure decides if a response counts as failure, by default network errors, 429 and 5xx do.
+	// other 4xx are problems with the request itself so they don't say anything about pushy's health.
+	// requests which ended because their context was done aren't passed to it and don't count either way
+	IsFailure func(response *http.Response, err error) bool
+	// OnStateChange is called after state changes, it's called synchronously so it shouldn't block
+	OnStateChange func(from CircuitState, to CircuitState)
+}
+
+// DefaultIsFailure treats network errors, 429 and 5xx responses as failures,
+// requests cancelled by the caller are not failures
+func DefaultIsFailure(response *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+}
+
+// CircuitBreaker is an IHTTPClient which stops calling client after it fails repeatedly,
+// so callers fail fast with ErrCircuitOpen instead of waiting on timeouts during an outage
+//  breaker := pushy.NewCircuitBreaker(pushy.GetDefaultHTTPClient(10*time.Second), pushy.CircuitBreakerConfig{})
+//  sdk.SetHTTPClient(breaker)
+// it's safe to use from multiple goroutines
+type CircuitBreaker struct {
+	client IHTTPClient
+	config CircuitBreakerConfig
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	trials    int
+	successes int
+	// generation changes with every state change, so outcomes of requests started in an older state are ignored
+	generation uint64
+}
+
+// NewCircuitBreaker wraps client with a circuit breaker
+func NewCircuitBreaker(client IHTTPClient, config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultIsFailure
+	}
+	return &CircuitBreaker{client: client, config: config}
+}
+
+// State returns current state, an open circuit whose cooldown has passed is reported as half-open
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	state := b.state
+	if state == CircuitOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		state = CircuitHalfOpen
+	}
+	b.mu.Unlock()
+	return state
+}
+
+// Get makes a GET request unless circuit is open
+func (b *CircuitBreaker) Get(url string) (*http.Response, error) {
+	return b.call(context.Background(), func() (*http.Response, error) { return b.client.Get(url) })
+}
+
+// Post makes a POST request unless circuit is open
+func (b *CircuitBreaker) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return b.call(context.Background(), func() (*http.Response, error) { return b.client.Post(url, contentType, body) })
+}
+
+// Do sends request unless circuit is open
+func (b *CircuitBreaker) Do(request *http.Request) (*http.Response, error) {
+	return b.call(request.Context(), func() (*http.Response, error) { return b.client.Do(request) })
+}
+
+// call makes request unless circuit is open, requests which ended because ctx of the caller was done
+// (like a tight WithTimeout) say nothing about pushy's health so they aren't counted
+func (b *CircuitBreaker) call(ctx context.Context, request func() (*http.Response, error)) (*http.Response, error) {
+	generation, err := b.before()
+	if err != nil {
+		return nil, err
+	}
+	response, err := request()
+	if err != nil && ctx.Err() != nil {
+		b.release(generation)
+		return response, err
+	}
+	b.after(generation, b.config.IsFailure(response, err))
+	return response, err
+}
+
+// release gives back the trial slot of a request which was let through by before without recording its outcome
+func (b *CircuitBreaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation == b.generation && b.state == CircuitHalfOpen {
+		b.trials--
+	}
+}
+
+// before reserves a slot for the request or rejects it
+func (b *CircuitBreaker) before() (uint64, error) {
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.config.Cooldown {
+			return 0, ErrCircuitOpen
+		}
+		changed = b.setState(CircuitHalfOpen)
+	}
+	if b.state == CircuitHalfOpen {
+		if b.trials >= b.config.HalfOpenRequests {
+			return 0, ErrCircuitOpen
+		}
+		b.trials++
+	}
+	return b.generation, nil
+}
+
+// after records the outcome of a request which was let through by before
+func (b *CircuitBreaker) after(generation uint64, failed bool) {
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+	if generation != b.generation {
+		return
+	}
+	switch b.state {
+	case CircuitClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			changed = b.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		b.trials--
+		if failed {
+			changed = b.setState(CircuitOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			changed = b.setState(CircuitClosed)
+		}
+	}
+}
+
+// setState must be called with mu held, it returns the callback to call after mu is released
+func (b *CircuitBreaker) setState(state CircuitState) func() {
+	from := b.state
+	b.state = state
+	b.failures = 0
+	b.trials = 0
+	b.successes = 0
+	b.generation++
+	if state == CircuitOpen {
+		b.openedAt = time.Now()
+	}
+	if b.config.OnStateChange == nil {
+		return nil
+	}
+	return func() { b.config.OnStateChange(from, state) }
+}
@@ -0,0 +1,214 @@
+package pushy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedClient answers with the status code returned by respond, or with err if it's set
+type scriptedClient struct {
+	mu      sync.Mutex
+	calls   int
+	status  int
+	err     error
+	release chan struct{}
+}
+
+func (c *scriptedClient) respond() (*http.Response, error) {
+	c.mu.Lock()
+	c.calls++
+	status, err, release := c.status, c.err, c.release
+	c.mu.Unlock()
+	if release != nil {
+		<-release
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+}
+
+func (c *scriptedClient) set(status int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status, c.err = status, err
+}
+
+func (c *scriptedClient) Get(string) (*http.Response, error) {
+	return c.respond()
+}
+
+func (c *scriptedClient) Post(string, string, io.Reader) (*http.Response, error) {
+	return c.respond()
+}
+
+func (c *scriptedClient) Do(*http.Request) (*http.Response, error) {
+	return c.respond()
+}
+
+type stateChanges struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (s *stateChanges) record(from pushy.CircuitState, to pushy.CircuitState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, from.String()+" -> "+to.String())
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	Assert := assert.New(t)
+	upstream := &scriptedClient{status: 500}
+	changes := &stateChanges{}
+	breaker := pushy.NewCircuitBreaker(upstream, pushy.CircuitBreakerConfig{
+		FailureThreshold: 3,
+		Cooldown:         20 * time.Millisecond,
+		OnStateChange:    changes.record,
+	})
+	client := pushy.Create("SECRET", "https://api.pushy.me")
+	client.SetHTTPClient(breaker)
+
+	for i := 0; i < 3; i++ {
+		_, _, err := client.Topics()
+		Assert.Contains(err.Error(), "500")
+	}
+	Assert.Equal(pushy.CircuitOpen, breaker.State())
+	_, _, err := client.DeleteNotification("PUSH")
+	Assert.Equal(pushy.ErrCircuitOpen, err)
+	_, _, err = client.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Equal(pushy.ErrCircuitOpen, err)
+	Assert.Equal(3, upstream.calls, "open circuit doesn't call upstream")
+
+	// trial request fails, circuit opens again
+	time.Sleep(20 * time.Millisecond)
+	Assert.Equal(pushy.CircuitHalfOpen, breaker.State())
+	_, _, err = client.Topics()
+	Assert.Contains(err.Error(), "500")
+	Assert.Equal(pushy.CircuitOpen, breaker.State())
+
+	// trial request succeeds, circuit closes
+	time.Sleep(20 * time.Millisecond)
+	upstream.set(200, nil)
+	_, _, err = client.Topics()
+	Assert.Nil(err)
+	Assert.Equal(pushy.CircuitClosed, breaker.State())
+	Assert.Equal([]string{
+		"closed -> open",
+		"open -> half-open",
+		"half-open -> open",
+		"open -> half-open",
+		"half-open -> closed",
+	}, changes.changes)
+}
+
+func TestCircuitBreaker_FailureClassification(t *testing.T) {
+	Assert := assert.New(t)
+	upstream := &scriptedClient{status: 400}
+	breaker := pushy.NewCircuitBreaker(upstream, pushy.CircuitBreakerConfig{FailureThreshold: 2})
+	for i := 0; i < 5; i++ {
+		breaker.Get("https://api.pushy.me/topics")
+	}
+	Assert.Equal(pushy.CircuitClosed, breaker.State(), "bad requests don't open circuit")
+
+	upstream.set(0, errors.New("connection refused"))
+	breaker.Get("https://api.pushy.me/topics")
+	upstream.set(200, nil)
+	breaker.Get("https://api.pushy.me/topics")
+	upstream.set(429, nil)
+	breaker.Get("https://api.pushy.me/topics")
+	Assert.Equal(pushy.CircuitClosed, breaker.State(), "failures have to be consecutive")
+	breaker.Get("https://api.pushy.me/topics")
+	Assert.Equal(pushy.CircuitOpen, breaker.State())
+
+	Assert.True(pushy.DefaultIsFailure(&http.Response{StatusCode: 503}, nil))
+	Assert.False(pushy.DefaultIsFailure(&http.Response{StatusCode: 404}, nil))
+	Assert.False(pushy.DefaultIsFailure(nil, fmt.Errorf("request: %w", context.Canceled)), "caller cancelling isn't pushy failing")
+	Assert.Equal("half-open", pushy.CircuitHalfOpen.String())
+}
+
+func TestCircuitBreaker_HalfOpenLimitsTrials(t *testing.T) {
+	Assert := assert.New(t)
+	upstream := &scriptedClient{status: 500}
+	breaker := pushy.NewCircuitBreaker(upstream, pushy.CircuitBreakerConfig{
+		FailureThreshold: 1,
+		Cooldown:         time.Millisecond,
+		HalfOpenRequests: 2,
+	})
+	breaker.Get("https://api.pushy.me/topics")
+	time.Sleep(time.Millisecond)
+
+	upstream.set(200, nil)
+	upstream.release = make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := breaker.Get("https://api.pushy.me/topics")
+			assert.Nil(t, err)
+		}()
+	}
+	waitFor(t, func() bool {
+		upstream.mu.Lock()
+		defer upstream.mu.Unlock()
+		return upstream.calls == 3
+	})
+	_, err := breaker.Get("https://api.pushy.me/topics")
+	Assert.Equal(pushy.ErrCircuitOpen, err, "only 2 trials run at once")
+	close(upstream.release)
+	wg.Wait()
+	Assert.Equal(pushy.CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_CallerTimeout(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetDelay(50 * time.Millisecond)
+	sdk := pushy.Create("SECRET", server.URL)
+	breaker := pushy.NewCircuitBreaker(pushy.GetDefaultHTTPClient(time.Second), pushy.CircuitBreakerConfig{FailureThreshold: 2})
+	sdk.SetHTTPClient(breaker)
+
+	// requests running out of the caller's own time say nothing about pushy
+	for i := 0; i < 3; i++ {
+		_, _, err := sdk.DeviceInfoWithOptions("DEVICE", pushy.WithTimeout(5*time.Millisecond))
+		Assert.True(errors.Is(err, context.DeadlineExceeded), "%v", err)
+	}
+	Assert.Equal(pushy.CircuitClosed, breaker.State())
+
+	// pushy being slower than the http client allows does
+	slow := pushy.NewCircuitBreaker(pushy.GetDefaultHTTPClient(5*time.Millisecond), pushy.CircuitBreakerConfig{FailureThreshold: 2})
+	sdk.SetHTTPClient(slow)
+	for i := 0; i < 2; i++ {
+		sdk.DeviceInfo("DEVICE")
+	}
+	Assert.Equal(pushy.CircuitOpen, slow.State())
+}
+
+func TestCircuitBreaker_StopsRetries(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetStatus(http.StatusServiceUnavailable)
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.NewCircuitBreaker(pushy.GetDefaultHTTPClient(time.Second), pushy.CircuitBreakerConfig{FailureThreshold: 1}))
+
+	start := time.Now()
+	_, _, err := sdk.DeviceInfoWithOptions("DEVICE", pushy.WithRetry(pushy.RetryPolicy{Attempts: 5, Backoff: 50 * time.Millisecond}))
+	Assert.True(errors.Is(err, pushy.ErrCircuitOpen), "%v", err)
+	Assert.Len(server.Requests(), 1)
+	Assert.True(time.Since(start) < 140*time.Millisecond, "retrying stops once the circuit opens")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fossapps/pushy/internal/callers"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// operations maps methods of the service to operations callers can be allowed to use,
+// methods which aren't listed can't be called by anyone
+var operations = map[string]string{
+	pushypb.PushyService_NotifyDevice_FullMethodName:         callers.OpSend,
+	pushypb.PushyService_NotifyDevices_FullMethodName:        callers.OpSend,
+	pushypb.PushyService_DeviceInfo_FullMethodName:           callers.OpDeviceInfo,
+	pushypb.PushyService_DevicePresence_FullMethodName:       callers.OpDevicePresence,
+	pushypb.PushyService_NotificationStatus_FullMethodName:   callers.OpPushStatus,
+	pushypb.PushyService_DeleteNotification_FullMethodName:   callers.OpPushDelete,
+	pushypb.PushyService_SubscribeToTopic_FullMethodName:     callers.OpTopicSubscribe,
+	pushypb.PushyService_UnsubscribeFromTopic_FullMethodName: callers.OpTopicUnsubscribe,
+	pushypb.PushyService_Topics_FullMethodName:               callers.OpTopicList,
+}
+
+// authenticator lets callers use the methods they are allowed to as often as they are allowed to, they authenticate with
+// "authorization: Bearer <key>" metadata (see pushygrpc.APIKey)
+type authenticator struct {
+	callers []callers.Caller
+	limiter *callers.Limiter
+	audit   *log.Logger
+}
+
+func newAuthenticator(allowed []callers.Caller, audit *log.Logger) *authenticator {
+	return &authenticator{
+		callers: allowed,
+		limiter: callers.NewLimiter(),
+		audit:   audit,
+	}
+}
+
+// calls is how many requests to pushy req makes, every request of a batch counts against the rate limit
+func calls(req interface{}) int {
+	if batch, ok := req.(*pushypb.BatchSendRequest); ok && len(batch.GetRequests()) > 1 {
+		return len(batch.GetRequests())
+	}
+	return 1
+}
+
+// authorize returns name of the caller, or status error if it can't call method making n requests to pushy
+func (a *authenticator) authorize(ctx context.Context, method string, n int) (string, error) {
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) == 1 {
+			key = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+	c, ok := callers.Find(a.callers, key)
+	if !ok {
+		return "-", status.Error(codes.Unauthenticated, "missing or invalid api key")
+	}
+	operation, ok := operations[method]
+	if !ok || !c.Allows(operation) {
+		return c.Name, status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", c.Name, method)
+	}
+	if burst := c.BurstSize(); c.RequestsPerSecond > 0 && n > burst {
+		return c.Name, status.Errorf(codes.InvalidArgument, "batch of %d requests is over the %d %s can make at once", n, burst, c.Name)
+	}
+	if ok, wait := a.limiter.AllowN(c, n); !ok {
+		return c.Name, status.Errorf(codes.ResourceExhausted, "%s is over its rate limit, retry in %s", c.Name, wait.Round(time.Millisecond))
+	}
+	return c.Name, nil
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	start := time.Now()
+	name, err := a.authorize(ctx, info.FullMethod, calls(req))
+	defer func() {
+		a.audit.Printf("caller=%s method=%s code=%s duration=%s", name, info.FullMethod, status.Code(err), time.Since(start))
+	}()
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream makes sure streaming methods, if the service ever gets any, aren't left without authentication
+func (a *authenticator) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	name, err := a.authorize(stream.Context(), info.FullMethod, 1)
+	defer func() {
+		a.audit.Printf("caller=%s method=%s code=%s duration=%s", name, info.FullMethod, status.Code(err), time.Since(start))
+	}()
+	if err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
@@ -0,0 +1,133 @@
+// Command pushy-grpc serves pushy over gRPC (see pushygrpc package),
+// pushy secret key is read from PUSHY_API_TOKEN, audit log is written to stderr.
+// every caller has its own api key and list of operations it's allowed to use, like with pushy-relay:
+//  [
+//    {"name": "billing", "key": "...", "operations": ["send", "device.info"], "requests_per_second": 10}
+//  ]
+// callers going over requests_per_second get ResourceExhausted, every request of a NotifyDevices batch counts
+// and batches larger than burst are rejected with InvalidArgument.
+// keys are sent as "authorization: Bearer <key>" metadata (see pushygrpc.APIKey).
+// without -tls-cert keys travel in plain text, so it refuses to listen on anything but loopback
+// unless -insecure is given, -client-ca additionally requires callers to present a certificate signed by it
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/callers"
+	"github.com/fossapps/pushy/pushygrpc"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func loadCallers(path string) ([]callers.Caller, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var c []callers.Caller
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, callers.Validate(c)
+}
+
+// isLoopback reports whether address only accepts connections from the same host,
+// an address without host listens on every interface
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serverOptions returns tls credentials if certFile is set, clientCA turns on mTLS.
+// plain text is only allowed on loopback, or anywhere with insecure
+func serverOptions(listen, certFile, keyFile, clientCA string, insecure bool) ([]grpc.ServerOption, error) {
+	if certFile == "" {
+		if clientCA != "" {
+			return nil, errors.New("-client-ca needs -tls-cert and -tls-key")
+		}
+		if !isLoopback(listen) && !insecure {
+			return nil, fmt.Errorf("refusing to serve %s without tls, set -tls-cert and -tls-key or pass -insecure", listen)
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCA != "" {
+		pem, err := ioutil.ReadFile(clientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificates found", clientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(config))}, nil
+}
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:9090", "address to listen on")
+	endpoint := flag.String("endpoint", pushy.GetDefaultAPIEndpoint(), "pushy api endpoint")
+	timeout := flag.Duration("timeout", 10*time.Second, "http timeout for requests to pushy")
+	callersPath := flag.String("callers", "callers.json", "file with callers and operations they are allowed to use")
+	certFile := flag.String("tls-cert", "", "tls certificate file")
+	keyFile := flag.String("tls-key", "", "tls key file")
+	clientCA := flag.String("client-ca", "", "require client certificates signed by ca in this file")
+	insecure := flag.Bool("insecure", false, "allow serving without tls on addresses other than loopback")
+	flag.Parse()
+	allowed, err := loadCallers(*callersPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	options, err := serverOptions(*listen, *certFile, *keyFile, *clientCA, *insecure)
+	if err != nil {
+		log.Fatal(err)
+	}
+	token := os.Getenv("PUSHY_API_TOKEN")
+	if token == "" {
+		log.Fatal("PUSHY_API_TOKEN is not set")
+	}
+	sdk := pushy.Create(token, *endpoint)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(*timeout))
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth := newAuthenticator(allowed, log.New(os.Stderr, "audit ", log.LstdFlags|log.LUTC))
+	options = append(options, grpc.UnaryInterceptor(auth.unary), grpc.StreamInterceptor(auth.stream))
+	server := grpc.NewServer(options...)
+	pushypb.RegisterPushyServiceServer(server, pushygrpc.NewServer(sdk))
+	log.Printf("listening on %s", *listen)
+	log.Fatal(server.Serve(listener))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/callers"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/fossapps/pushy/pushygrpc"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts a server relaying to upstream with callers billing, admin, limited and batcher, connect returns a client using key
+func serve(t *testing.T, upstream *pushytest.Server, audit *bytes.Buffer) (connect func(key string) *pushygrpc.Client, cleanup func()) {
+	sdk := pushy.Create("SECRET", upstream.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	auth := newAuthenticator([]callers.Caller{
+		{Name: "billing", Key: "billing-key", Operations: []string{callers.OpSend, callers.OpDeviceInfo}},
+		{Name: "admin", Key: "admin-key", Operations: []string{"*"}},
+		{Name: "limited", Key: "limited-key", Operations: []string{"*"}, RequestsPerSecond: 0.5, Burst: 1},
+		{Name: "batcher", Key: "batcher-key", Operations: []string{callers.OpSend}, RequestsPerSecond: 0.5, Burst: 3},
+	}, log.New(audit, "", 0))
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(auth.unary), grpc.StreamInterceptor(auth.stream))
+	pushypb.RegisterPushyServiceServer(server, pushygrpc.NewServer(sdk))
+	go server.Serve(listener)
+	var conns []*grpc.ClientConn
+	connect = func(key string) *pushygrpc.Client {
+		options := []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}
+		if key != "" {
+			options = append(options, grpc.WithPerRPCCredentials(pushygrpc.APIKey(key)))
+		}
+		conn, err := grpc.NewClient("passthrough:///bufnet", options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+		return pushygrpc.NewClient(conn, time.Second)
+	}
+	return connect, func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		server.Stop()
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	Assert := assert.New(t)
+	upstream := pushytest.NewServer()
+	defer upstream.Close()
+	audit := &bytes.Buffer{}
+	connect, cleanup := serve(t, upstream, audit)
+	defer cleanup()
+
+	billing := connect("billing-key")
+	res, _, err := billing.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Equal("PUSH1", res.ID)
+	_, _, err = billing.DeviceInfo("DEVICE")
+	Assert.Nil(err)
+
+	_, _, err = billing.DeleteNotification("PUSH_ID")
+	Assert.Equal(codes.PermissionDenied, status.Code(err))
+	Assert.Contains(err.Error(), "billing is not allowed to call /pushy.v1.PushyService/DeleteNotification")
+
+	_, _, err = connect("admin-key").DeleteNotification("PUSH_ID")
+	Assert.Nil(err)
+
+	for _, key := range []string{"", "wrong-key"} {
+		_, _, err = connect(key).DeviceInfo("DEVICE")
+		Assert.Equal(codes.Unauthenticated, status.Code(err), key)
+	}
+	limited := connect("limited-key")
+	_, _, err = limited.DeviceInfo("DEVICE")
+	Assert.Nil(err)
+	_, _, err = limited.DeviceInfo("DEVICE")
+	Assert.Equal(codes.ResourceExhausted, status.Code(err))
+	Assert.Equal(4, len(upstream.Requests()), "rejected calls aren't relayed")
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	Assert.Equal(8, len(lines))
+	Assert.True(strings.HasPrefix(lines[0], "caller=billing method=/pushy.v1.PushyService/NotifyDevice code=OK"), lines[0])
+	Assert.True(strings.HasPrefix(lines[2], "caller=billing method=/pushy.v1.PushyService/DeleteNotification code=PermissionDenied"), lines[2])
+	Assert.True(strings.HasPrefix(lines[4], "caller=- method=/pushy.v1.PushyService/DeviceInfo code=Unauthenticated"), lines[4])
+}
+
+func TestAuthenticator_Batch(t *testing.T) {
+	Assert := assert.New(t)
+	upstream := pushytest.NewServer()
+	defer upstream.Close()
+	connect, cleanup := serve(t, upstream, &bytes.Buffer{})
+	defer cleanup()
+	batcher := connect("batcher-key")
+	batch := func(n int) []pushy.SendNotificationRequest {
+		requests := make([]pushy.SendNotificationRequest, n)
+		for i := range requests {
+			requests[i] = pushy.SendNotificationRequest{To: []string{"D"}}
+		}
+		return requests
+	}
+
+	// every request of a batch counts against the rate limit
+	_, err := batcher.NotifyDevices(batch(4))
+	Assert.Equal(codes.InvalidArgument, status.Code(err), "batch can't be over burst")
+	_, err = batcher.NotifyDevices(batch(2))
+	Assert.Nil(err)
+	_, err = batcher.NotifyDevices(batch(2))
+	Assert.Equal(codes.ResourceExhausted, status.Code(err))
+	_, _, err = batcher.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Len(upstream.Pushes(), 3)
+}
+
+func TestServerOptions(t *testing.T) {
+	Assert := assert.New(t)
+	for _, address := range []string{"127.0.0.1:9090", "localhost:9090", "[::1]:9090"} {
+		options, err := serverOptions(address, "", "", "", false)
+		Assert.Nil(err, address)
+		Assert.Nil(options, address)
+	}
+	for _, address := range []string{":9090", "0.0.0.0:9090", "10.0.0.1:9090", "example.com:9090"} {
+		_, err := serverOptions(address, "", "", "", false)
+		if Assert.NotNil(err, address) {
+			Assert.Contains(err.Error(), "without tls")
+		}
+		_, err = serverOptions(address, "", "", "", true)
+		Assert.Nil(err, "-insecure allows plain text on %s", address)
+	}
+	_, err := serverOptions("127.0.0.1:9090", "", "", "ca.pem", false)
+	Assert.EqualError(err, "-client-ca needs -tls-cert and -tls-key")
+	_, err = serverOptions("127.0.0.1:9090", "missing.pem", "missing.key", "", false)
+	Assert.NotNil(err)
+}
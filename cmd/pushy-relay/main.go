@@ -0,0 +1,87 @@
+// Command pushy-relay exposes pushy over an internal http api, so services can send notifications
+// without having access to the pushy secret key.
+// every caller has its own api key and list of operations it's allowed to use:
+//  {
+//    "listen": ":8080",
+//    "api_endpoint": "https://api.pushy.me",
+//    "timeout": "10s",
+//    "callers": [
+//      {"name": "billing", "key": "...", "operations": ["send", "device.info"], "requests_per_second": 10, "burst": 20}
+//    ]
+//  }
+// callers going over requests_per_second get 429 with Retry-After. requests to pushy are retried, sends with
+// an idempotency key, callers can give their own with Idempotency-Key header. requests pushy rejects as invalid
+// are answered with the status pushy gave (400, 404 or 413), pushy rate limiting the relay is 503 with Retry-After,
+// pushy rejecting the secret key, failing or being unreachable is 502.
+// pushy secret key is read from PUSHY_API_TOKEN, audit log is written to stderr
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/callers"
+)
+
+type config struct {
+	Listen      string           `json:"listen"`
+	APIEndpoint string           `json:"api_endpoint"`
+	Timeout     string           `json:"timeout"`
+	Callers     []callers.Caller `json:"callers"`
+}
+
+func loadConfig(path string) (config, error) {
+	c := config{
+		Listen:      ":8080",
+		APIEndpoint: pushy.GetDefaultAPIEndpoint(),
+		Timeout:     "10s",
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, c.validate()
+}
+
+func (c config) validate() error {
+	if _, err := time.ParseDuration(c.Timeout); err != nil {
+		return fmt.Errorf("timeout: %v", err)
+	}
+	return callers.Validate(c.Callers)
+}
+
+func main() {
+	configPath := flag.String("config", "relay.json", "config file")
+	flag.Parse()
+	c, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	token := os.Getenv("PUSHY_API_TOKEN")
+	if token == "" {
+		log.Fatal("PUSHY_API_TOKEN is not set")
+	}
+	timeout, _ := time.ParseDuration(c.Timeout)
+	sdk := pushy.Create(token, c.APIEndpoint)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(timeout))
+	audit := log.New(os.Stderr, "audit ", log.LstdFlags|log.LUTC)
+	server := &http.Server{
+		Addr:              c.Listen,
+		Handler:           newRelay(sdk, c.Callers, audit),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("listening on %s", c.Listen)
+	log.Fatal(server.ListenAndServe())
+}
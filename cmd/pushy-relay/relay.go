@@ -0,0 +1,269 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/callers"
+)
+
+// relay exposes a pushy.IPushyClientWithOptions over http, so the pushy secret key stays with the relay
+type relay struct {
+	client  pushy.IPushyClientWithOptions
+	callers []callers.Caller
+	limiter *callers.Limiter
+	// retry is the policy requests to pushy are retried with, sends are given an idempotency key so it's safe
+	retry pushy.RetryPolicy
+	audit *log.Logger
+}
+
+func newRelay(client pushy.IPushyClientWithOptions, allowed []callers.Caller, audit *log.Logger) *relay {
+	return &relay{
+		client:  client,
+		callers: allowed,
+		limiter: callers.NewLimiter(),
+		retry:   pushy.RetryPolicy{Attempts: 3, MaxBackoff: 2 * time.Second},
+		audit:   audit,
+	}
+}
+
+// statusRecorder remembers status code for audit log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	operation, target := route(req)
+	name := "-"
+	defer func() {
+		r.audit.Printf("caller=%s op=%s target=%q status=%d duration=%s", name, operation, target, recorder.status, time.Since(start))
+	}()
+	if operation == "" {
+		writeError(recorder, http.StatusNotFound, "not found")
+		return
+	}
+	c, ok := r.authenticate(req)
+	if !ok {
+		writeError(recorder, http.StatusUnauthorized, "missing or invalid api key")
+		return
+	}
+	name = c.Name
+	if !c.Allows(operation) {
+		writeError(recorder, http.StatusForbidden, fmt.Sprintf("%s is not allowed to %s", c.Name, operation))
+		return
+	}
+	if ok, wait := r.limiter.Allow(c); !ok {
+		recorder.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeError(recorder, http.StatusTooManyRequests, fmt.Sprintf("%s is over its rate limit", c.Name))
+		return
+	}
+	r.handle(recorder, req, c, operation, target)
+}
+
+func (r *relay) authenticate(req *http.Request) (callers.Caller, bool) {
+	return callers.Find(r.callers, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+}
+
+// route maps method and path to an operation, target is the id in path (if any)
+//  POST   /v1/send
+//  GET    /v1/devices/{id}
+//  POST   /v1/devices/presence
+//  GET    /v1/pushes/{id}
+//  DELETE /v1/pushes/{id}
+//  POST   /v1/topics/subscribe
+//  POST   /v1/topics/unsubscribe
+//  GET    /v1/topics
+func route(req *http.Request) (string, string) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/")
+	if path == req.URL.Path {
+		return "", ""
+	}
+	switch {
+	case req.Method == http.MethodPost && path == "send":
+		return callers.OpSend, ""
+	case req.Method == http.MethodPost && path == "devices/presence":
+		return callers.OpDevicePresence, ""
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "devices/"):
+		return callers.OpDeviceInfo, strings.TrimPrefix(path, "devices/")
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "pushes/"):
+		return callers.OpPushStatus, strings.TrimPrefix(path, "pushes/")
+	case req.Method == http.MethodDelete && strings.HasPrefix(path, "pushes/"):
+		return callers.OpPushDelete, strings.TrimPrefix(path, "pushes/")
+	case req.Method == http.MethodPost && path == "topics/subscribe":
+		return callers.OpTopicSubscribe, ""
+	case req.Method == http.MethodPost && path == "topics/unsubscribe":
+		return callers.OpTopicUnsubscribe, ""
+	case req.Method == http.MethodGet && path == "topics":
+		return callers.OpTopicList, ""
+	}
+	return "", ""
+}
+
+func (r *relay) handle(w http.ResponseWriter, req *http.Request, c callers.Caller, operation string, target string) {
+	if target == "" && (operation == callers.OpDeviceInfo || operation == callers.OpPushStatus || operation == callers.OpPushDelete) || strings.Contains(target, "/") {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var meta pushy.ResponseMeta
+	opts := []pushy.CallOption{pushy.WithRetry(r.retry), pushy.WithMeta(&meta)}
+	reply := func(result interface{}, pushyErr *pushy.Error, err error) {
+		respond(w, result, pushyErr, err, meta)
+	}
+	switch operation {
+	case callers.OpSend:
+		var request pushy.SendNotificationRequest
+		if !decode(w, req, &request) {
+			return
+		}
+		if err := request.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		key, err := idempotencyKey(c, req)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		res, pushyErr, err := r.client.NotifyDeviceWithOptions(request, append(opts, pushy.WithIdempotencyKey(key))...)
+		reply(res, pushyErr, err)
+	case callers.OpDeviceInfo:
+		res, pushyErr, err := r.client.DeviceInfoWithOptions(target, opts...)
+		reply(res, pushyErr, err)
+	case callers.OpDevicePresence:
+		var request pushy.DevicePresenceRequest
+		if !decode(w, req, &request) {
+			return
+		}
+		if len(request.Tokens) == 0 {
+			writeError(w, http.StatusBadRequest, "tokens: at least one token is required")
+			return
+		}
+		res, pushyErr, err := r.client.DevicePresenceWithOptions(request.Tokens, opts...)
+		reply(res, pushyErr, err)
+	case callers.OpPushStatus:
+		res, pushyErr, err := r.client.NotificationStatusWithOptions(target, opts...)
+		reply(res, pushyErr, err)
+	case callers.OpPushDelete:
+		res, pushyErr, err := r.client.DeleteNotificationWithOptions(target, opts...)
+		reply(res, pushyErr, err)
+	case callers.OpTopicSubscribe, callers.OpTopicUnsubscribe:
+		var request pushy.DeviceSubscriptionRequest
+		if !decode(w, req, &request) {
+			return
+		}
+		if request.Token == "" || len(request.Topics) == 0 {
+			writeError(w, http.StatusBadRequest, "token and topics are required")
+			return
+		}
+		if operation == callers.OpTopicSubscribe {
+			res, pushyErr, err := r.client.SubscribeToTopicWithOptions(request.Token, request.Topics, opts...)
+			reply(res, pushyErr, err)
+			return
+		}
+		res, pushyErr, err := r.client.UnsubscribeFromTopicWithOptions(request.Token, request.Topics, opts...)
+		reply(res, pushyErr, err)
+	case callers.OpTopicList:
+		lister, ok := r.client.(pushy.ITopicsClient)
+		if !ok {
+			writeError(w, http.StatusNotImplemented, "listing topics isn't supported by this relay")
+			return
+		}
+		res, pushyErr, err := lister.Topics(opts...)
+		reply(res, pushyErr, err)
+	}
+}
+
+// idempotencyKey returns the key a send is made with, so pushy can tell its retries apart from new sends.
+// callers can give their own with Idempotency-Key header to make their retries safe as well,
+// keys are prefixed with the caller name so callers can't collide with each other
+func idempotencyKey(c callers.Caller, req *http.Request) (string, error) {
+	if key := req.Header.Get("Idempotency-Key"); key != "" {
+		return c.Name + ":" + key, nil
+	}
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return c.Name + ":" + hex.EncodeToString(random), nil
+}
+
+// maxBodySize limits size of request bodies accepted from callers
+const maxBodySize = 1 << 20
+
+func decode(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// respond passes pushy response through. requests pushy rejected as invalid (400, 404 and 413) keep its status,
+// pushy rate limiting the relay is 503 with Retry-After. anything else, like pushy rejecting the relay's own
+// secret key, pushy failing or being unreachable, is a bad gateway
+func respond(w http.ResponseWriter, result interface{}, pushyErr *pushy.Error, err error, meta pushy.ResponseMeta) {
+	if pushyErr != nil || err != nil {
+		status := http.StatusBadGateway
+		var statusErr pushy.StatusError
+		if errors.As(err, &statusErr) {
+			switch statusErr.Code {
+			case http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge:
+				status = statusErr.Code
+			case http.StatusUnauthorized, http.StatusForbidden:
+				writeError(w, status, "pushy rejected the relay's secret key")
+				return
+			case http.StatusTooManyRequests:
+				status = http.StatusServiceUnavailable
+				w.Header().Set("Retry-After", retryAfter(meta))
+			}
+		}
+		if pushyErr != nil {
+			writeError(w, status, fmt.Sprintf("pushy: %s", pushyErr.Error))
+			return
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// retryAfter is the Retry-After pushy sent, or time until its rate limit resets, at least a second
+func retryAfter(meta pushy.ResponseMeta) string {
+	if seconds, err := strconv.Atoi(meta.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return strconv.Itoa(seconds)
+	}
+	if wait := time.Until(meta.RateLimit.Reset); wait > time.Second {
+		return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+	}
+	return "1"
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, pushy.Error{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
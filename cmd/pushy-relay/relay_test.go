@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/callers"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEnv struct {
+	pushy *pushytest.Server
+	relay *httptest.Server
+	audit *bytes.Buffer
+	close func()
+}
+
+func setup(token string) testEnv {
+	upstream := pushytest.NewServer()
+	upstream.SetAPIKey("SECRET")
+	sdk := pushy.Create(token, upstream.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	allowed := []callers.Caller{
+		{Name: "billing", Key: "billing-key", Operations: []string{callers.OpSend, callers.OpDeviceInfo}},
+		{Name: "admin", Key: "admin-key", Operations: []string{"*"}},
+	}
+	audit := &bytes.Buffer{}
+	server := httptest.NewServer(newRelay(sdk, allowed, log.New(audit, "", 0)))
+	return testEnv{
+		pushy: upstream,
+		relay: server,
+		audit: audit,
+		close: func() {
+			server.Close()
+			upstream.Close()
+		},
+	}
+}
+
+func call(t *testing.T, env testEnv, key string, method string, path string, body string) (int, map[string]interface{}) {
+	req, err := http.NewRequest(method, env.relay.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var decoded map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&decoded)
+	return res.StatusCode, decoded
+}
+
+func TestRelay_Operations(t *testing.T) {
+	env := setup("SECRET")
+	defer env.close()
+	table := []struct {
+		method   string
+		path     string
+		body     string
+		upstream string
+	}{
+		{method: "POST", path: "/v1/send", body: `{"to":["DEVICE"],"data":"hi"}`, upstream: "POST /push"},
+		{method: "GET", path: "/v1/devices/DEVICE", upstream: "GET /devices/DEVICE"},
+		{method: "POST", path: "/v1/devices/presence", body: `{"tokens":["DEVICE"]}`, upstream: `POST /devices/presence {"tokens":["DEVICE"]}`},
+		{method: "GET", path: "/v1/pushes/PUSH_ID", upstream: "GET /pushes/PUSH_ID"},
+		{method: "DELETE", path: "/v1/pushes/PUSH_ID", upstream: "DELETE /pushes/PUSH_ID"},
+		{method: "POST", path: "/v1/topics/subscribe", body: `{"token":"DEVICE","topics":["news"]}`, upstream: `POST /devices/subscribe {"token":"DEVICE","topics":["news"]}`},
+		{method: "POST", path: "/v1/topics/unsubscribe", body: `{"token":"DEVICE","topics":["news"]}`, upstream: `POST /devices/unsubscribe {"token":"DEVICE","topics":["news"]}`},
+		{method: "GET", path: "/v1/topics", upstream: "GET /topics"},
+	}
+	for i, data := range table {
+		status, _ := call(t, env, "admin-key", data.method, data.path, data.body)
+		assert.Equal(t, http.StatusOK, status, data.path)
+		assert.Contains(t, env.pushy.Requests()[i].String(), data.upstream)
+	}
+	status, body := call(t, env, "billing-key", "POST", "/v1/send", `{"to":["DEVICE"]}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "PUSH2", body["id"])
+	assert.Contains(t, env.audit.String(), `caller=billing op=send target="" status=200`)
+}
+
+func TestRelay_Rejects(t *testing.T) {
+	env := setup("SECRET")
+	defer env.close()
+	table := []struct {
+		key    string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{key: "", method: "GET", path: "/v1/topics", status: http.StatusUnauthorized},
+		{key: "wrong", method: "GET", path: "/v1/topics", status: http.StatusUnauthorized},
+		{key: "billing-key", method: "GET", path: "/v1/topics", status: http.StatusForbidden},
+		{key: "billing-key", method: "DELETE", path: "/v1/pushes/PUSH_ID", status: http.StatusForbidden},
+		{key: "admin-key", method: "GET", path: "/v2/topics", status: http.StatusNotFound},
+		{key: "admin-key", method: "PUT", path: "/v1/send", status: http.StatusNotFound},
+		{key: "admin-key", method: "POST", path: "/v1/send", body: `{"to":[]}`, status: http.StatusBadRequest},
+		{key: "admin-key", method: "POST", path: "/v1/send", body: `{"to":["A"],"unknown":1}`, status: http.StatusBadRequest},
+		{key: "admin-key", method: "POST", path: "/v1/send", body: `not json`, status: http.StatusBadRequest},
+		{key: "admin-key", method: "POST", path: "/v1/devices/presence", body: `{"tokens":[]}`, status: http.StatusBadRequest},
+		{key: "admin-key", method: "POST", path: "/v1/topics/subscribe", body: `{"token":"DEVICE"}`, status: http.StatusBadRequest},
+		{key: "admin-key", method: "GET", path: "/v1/devices/", status: http.StatusBadRequest},
+		{key: "admin-key", method: "GET", path: "/v1/pushes/a/b", status: http.StatusBadRequest},
+	}
+	for _, data := range table {
+		status, body := call(t, env, data.key, data.method, data.path, data.body)
+		assert.Equal(t, data.status, status, data.method+" "+data.path)
+		assert.NotEmpty(t, body["error"])
+	}
+	assert.Empty(t, env.pushy.Requests(), "nothing should reach pushy")
+	assert.Contains(t, env.audit.String(), "caller=- op=topic.list")
+	assert.Contains(t, env.audit.String(), "caller=billing op=topic.list target=\"\" status=403")
+}
+
+func TestRelay_UpstreamErrors(t *testing.T) {
+	env := setup("WRONG_SECRET")
+	defer env.close()
+	// pushy rejecting the relay's own key isn't the caller's fault
+	status, body := call(t, env, "admin-key", "GET", "/v1/devices/DEVICE", "")
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Equal(t, "pushy rejected the relay's secret key", body["error"])
+	assert.Len(t, env.pushy.Requests(), 1, "4xx isn't retried")
+
+	// invalid requests keep pushy's status
+	env.pushy.SetAPIKey("")
+	env.pushy.Reject(func(r pushytest.Request) bool { return r.Path == "/push" })
+	status, body = call(t, env, "admin-key", "POST", "/v1/send", `{"to":["DEVICE"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "pushy: rejected", body["error"])
+
+	// pushy failing is a bad gateway once retries run out
+	env.pushy.Reset()
+	env.pushy.SetStatus(http.StatusServiceUnavailable)
+	status, body = call(t, env, "admin-key", "GET", "/v1/devices/DEVICE", "")
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Equal(t, "pushy: unavailable", body["error"])
+	assert.Len(t, env.pushy.Requests(), 3)
+
+	unreachable := pushy.Create("SECRET", "http://127.0.0.1:1")
+	unreachable.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	server := httptest.NewServer(newRelay(unreachable, []callers.Caller{{Name: "admin", Key: "admin-key", Operations: []string{"*"}}}, log.New(ioutil.Discard, "", 0)))
+	defer server.Close()
+	status, _ = call(t, testEnv{relay: server}, "admin-key", "GET", "/v1/topics", "")
+	assert.Equal(t, http.StatusBadGateway, status)
+
+	// a client which doesn't list topics
+	noTopics := httptest.NewServer(newRelay(struct{ pushy.IPushyClientWithOptions }{unreachable}, []callers.Caller{{Name: "admin", Key: "admin-key", Operations: []string{"*"}}}, log.New(ioutil.Discard, "", 0)))
+	defer noTopics.Close()
+	status, _ = call(t, testEnv{relay: noTopics}, "admin-key", "GET", "/v1/topics", "")
+	assert.Equal(t, http.StatusNotImplemented, status)
+}
+
+func TestRelay_UpstreamRateLimit(t *testing.T) {
+	var attempts int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"rate limited"}`))
+	}))
+	defer upstream.Close()
+	sdk := pushy.Create("SECRET", upstream.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	r := newRelay(sdk, []callers.Caller{{Name: "admin", Key: "admin-key", Operations: []string{"*"}}}, log.New(ioutil.Discard, "", 0))
+	r.retry.MaxBackoff = time.Millisecond
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/v1/devices/DEVICE", nil)
+	req.Header.Set("Authorization", "Bearer admin-key")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, "relay being rate limited isn't the caller going over its limit")
+	assert.Equal(t, "7", res.Header.Get("Retry-After"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRelay_IdempotencyKey(t *testing.T) {
+	env := setup("SECRET")
+	defer env.close()
+	req, _ := http.NewRequest("POST", env.relay.URL+"/v1/send", strings.NewReader(`{"to":["DEVICE"]}`))
+	req.Header.Set("Authorization", "Bearer billing-key")
+	req.Header.Set("Idempotency-Key", "invoice-7")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	call(t, env, "billing-key", "POST", "/v1/send", `{"to":["DEVICE"]}`)
+	call(t, env, "billing-key", "POST", "/v1/send", `{"to":["DEVICE"]}`)
+
+	pushes := env.pushy.Pushes()
+	assert.Len(t, pushes, 3)
+	assert.Equal(t, "billing:invoice-7", pushes[0].Header.Get("Idempotency-Key"))
+	generated := pushes[1].Header.Get("Idempotency-Key")
+	assert.True(t, strings.HasPrefix(generated, "billing:"), generated)
+	assert.NotEqual(t, generated, pushes[2].Header.Get("Idempotency-Key"), "every send gets its own key")
+}
+
+func TestRelay_RateLimit(t *testing.T) {
+	upstream := pushytest.NewServer()
+	defer upstream.Close()
+	sdk := pushy.Create("SECRET", upstream.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	server := httptest.NewServer(newRelay(sdk, []callers.Caller{
+		{Name: "billing", Key: "billing-key", Operations: []string{"*"}, RequestsPerSecond: 0.5, Burst: 2},
+		{Name: "admin", Key: "admin-key", Operations: []string{"*"}},
+	}, log.New(ioutil.Discard, "", 0)))
+	defer server.Close()
+	env := testEnv{relay: server}
+
+	for i := 0; i < 2; i++ {
+		status, _ := call(t, env, "billing-key", "GET", "/v1/topics", "")
+		assert.Equal(t, http.StatusOK, status)
+	}
+	req, _ := http.NewRequest("GET", server.URL+"/v1/topics", nil)
+	req.Header.Set("Authorization", "Bearer billing-key")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "2", res.Header.Get("Retry-After"))
+	assert.Equal(t, 2, len(upstream.Requests()))
+
+	// every caller has its own limit
+	status, _ := call(t, env, "admin-key", "GET", "/v1/topics", "")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	table := []struct {
+		content string
+		valid   bool
+	}{
+		{content: `{"callers":[{"name":"a","key":"k","operations":["send"]}]}`, valid: true},
+		{content: `{"callers":[]}`},
+		{content: `{"callers":[{"name":"a"}]}`},
+		{content: `{"callers":[{"name":"a","key":"k"},{"name":"a","key":"l"}]}`},
+		{content: `{"timeout":"soon","callers":[{"name":"a","key":"k"}]}`},
+		{content: `{"listen":":80","typo":true}`},
+		{content: `{"callers":[{"name":"a","key":"k","requests_per_second":10,"burst":20}]}`, valid: true},
+		{content: `{"callers":[{"name":"a","key":"k","requests_per_second":-1}]}`},
+	}
+	for i, data := range table {
+		path := filepath.Join(dir, "relay.json")
+		ioutil.WriteFile(path, []byte(data.content), 0600)
+		c, err := loadConfig(path)
+		assert.Equal(t, data.valid, err == nil, i)
+		if data.valid {
+			assert.Equal(t, pushy.GetDefaultAPIEndpoint(), c.APIEndpoint)
+			assert.Equal(t, ":8080", c.Listen)
+		}
+	}
+	_, err = loadConfig(filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fossapps/pushy"
+)
+
+// config is the file stored at ~/.config/pushy/config.json
+//  {"api_token": "...", "api_endpoint": "https://api.pushy.me"}
+type config struct {
+	APIToken    string `json:"api_token"`
+	APIEndpoint string `json:"api_endpoint"`
+}
+
+// loadConfig reads config from path, $PUSHY_CONFIG or default location,
+// a missing file is only an error when path was explicitly given
+func loadConfig(path string, getenv env) (config, error) {
+	explicit := path != ""
+	if path == "" {
+		path = getenv("PUSHY_CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		if home := getenv("HOME"); home != "" {
+			path = filepath.Join(home, ".config", "pushy", "config.json")
+		}
+	}
+	var c config
+	if path == "" {
+		return c, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && !explicit {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// override applies flags and env on top of config file
+func (c *config) override(token string, endpoint string, getenv env) {
+	if value := getenv("PUSHY_API_TOKEN"); value != "" {
+		c.APIToken = value
+	}
+	if token != "" {
+		c.APIToken = token
+	}
+	if value := getenv("PUSHY_API_ENDPOINT"); value != "" {
+		c.APIEndpoint = value
+	}
+	if endpoint != "" {
+		c.APIEndpoint = endpoint
+	}
+	if c.APIEndpoint == "" {
+		c.APIEndpoint = pushy.GetDefaultAPIEndpoint()
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// errDryRun is returned by dryRunClient instead of a response
+var errDryRun = errors.New("dry run, request not sent")
+
+// dryRunClient is a pushy.IHTTPClient which prints requests instead of sending them
+type dryRunClient struct {
+	out io.Writer
+}
+
+func (c *dryRunClient) Get(url string) (*http.Response, error) {
+	return c.print(http.MethodGet, url, nil)
+}
+
+func (c *dryRunClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return c.print(http.MethodPost, url, body)
+}
+
+func (c *dryRunClient) Do(req *http.Request) (*http.Response, error) {
+	return c.print(req.Method, req.URL.String(), req.Body)
+}
+
+func (c *dryRunClient) print(method string, rawURL string, body io.Reader) (*http.Response, error) {
+	fmt.Fprintf(c.out, "%s %s\n", method, redact(rawURL))
+	if body == nil {
+		return nil, errDryRun
+	}
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, content, "", "  ") != nil {
+		indented.Reset()
+		indented.Write(content)
+	}
+	fmt.Fprintln(c.out, string(bytes.TrimSpace(indented.Bytes())))
+	return nil, errDryRun
+}
+
+// redact hides api key so dry run output can be pasted in tickets
+func redact(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := parsed.Query()
+	if _, ok := query["api_key"]; ok {
+		query.Set("api_key", "REDACTED")
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
@@ -0,0 +1,185 @@
+// Command pushy is a small command line tool to talk to pushy for debugging and one off operations
+//  pushy [flags] send -to DEVICE -data '{"message":"hi"}'
+//  pushy device info DEVICE
+//  pushy device presence DEVICE...
+//  pushy push status PUSH_ID
+//  pushy push delete PUSH_ID
+//  pushy topic subscribe DEVICE TOPIC...
+//  pushy topic unsubscribe DEVICE TOPIC...
+//  pushy topic list
+// api token is read from -token, PUSHY_API_TOKEN or config file (in that order)
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fossapps/pushy"
+)
+
+const usage = `usage: pushy [flags] <command> [args]
+
+commands:
+  send                             send a notification, see pushy send -h
+  device info DEVICE               show device info
+  device presence DEVICE...        show presence of devices
+  push status PUSH_ID              show status of a notification
+  push delete PUSH_ID              delete a pending notification
+  topic subscribe DEVICE TOPIC...  subscribe device to topics
+  topic unsubscribe DEVICE TOPIC.. unsubscribe device from topics
+  topic list                       list topics
+
+flags:
+`
+
+// env is used to read environment, replaced in tests
+type env func(string) string
+
+type cli struct {
+	sdk    pushy.IPushyClient
+	output string
+	dryRun bool
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, getenv env, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("pushy", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	token := flags.String("token", "", "pushy secret api key (default $PUSHY_API_TOKEN or config file)")
+	endpoint := flags.String("endpoint", "", "api endpoint (default "+pushy.GetDefaultAPIEndpoint()+")")
+	configPath := flags.String("config", "", "config file (default $PUSHY_CONFIG or ~/.config/pushy/config.json)")
+	output := flags.String("output", "table", "output format: json or table")
+	dryRun := flags.Bool("dry-run", false, "print the request instead of sending it")
+	timeout := flags.Duration("timeout", 10*time.Second, "http timeout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *output != "json" && *output != "table" {
+		fmt.Fprintf(stderr, "unknown output %q, use json or table\n", *output)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	config, err := loadConfig(*configPath, getenv)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	config.override(*token, *endpoint, getenv)
+	if config.APIToken == "" && !*dryRun {
+		fmt.Fprintln(stderr, "api token is missing, use -token, PUSHY_API_TOKEN or config file")
+		return 1
+	}
+	sdk := pushy.Create(config.APIToken, config.APIEndpoint)
+	if *dryRun {
+		sdk.SetHTTPClient(&dryRunClient{out: stdout})
+	} else {
+		sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(*timeout))
+	}
+	c := cli{sdk: sdk, output: *output, dryRun: *dryRun, stdin: stdin, stdout: stdout}
+	if err := c.dispatch(flags.Args(), stderr); err != nil {
+		fmt.Fprintln(stderr, err)
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// usageError is returned when command is called with wrong arguments
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func (c cli) dispatch(args []string, stderr io.Writer) error {
+	command, args := args[0], args[1:]
+	if command == "send" {
+		return c.send(args, stderr)
+	}
+	if len(args) == 0 {
+		return usageError(fmt.Sprintf("%s: missing sub command", command))
+	}
+	sub, args := args[0], args[1:]
+	switch command + " " + sub {
+	case "device info":
+		if len(args) != 1 {
+			return usageError("usage: pushy device info DEVICE")
+		}
+		res, pushyErr, err := c.sdk.DeviceInfo(args[0])
+		return c.print(res, pushyErr, err)
+	case "device presence":
+		if len(args) == 0 {
+			return usageError("usage: pushy device presence DEVICE...")
+		}
+		res, pushyErr, err := c.sdk.DevicePresence(args...)
+		return c.print(res, pushyErr, err)
+	case "push status":
+		if len(args) != 1 {
+			return usageError("usage: pushy push status PUSH_ID")
+		}
+		res, pushyErr, err := c.sdk.NotificationStatus(args[0])
+		return c.print(res, pushyErr, err)
+	case "push delete":
+		if len(args) != 1 {
+			return usageError("usage: pushy push delete PUSH_ID")
+		}
+		res, pushyErr, err := c.sdk.DeleteNotification(args[0])
+		return c.print(res, pushyErr, err)
+	case "topic subscribe":
+		if len(args) < 2 {
+			return usageError("usage: pushy topic subscribe DEVICE TOPIC...")
+		}
+		res, pushyErr, err := c.sdk.SubscribeToTopic(args[0], args[1:]...)
+		return c.print(res, pushyErr, err)
+	case "topic unsubscribe":
+		if len(args) < 2 {
+			return usageError("usage: pushy topic unsubscribe DEVICE TOPIC...")
+		}
+		res, pushyErr, err := c.sdk.UnsubscribeFromTopic(args[0], args[1:]...)
+		return c.print(res, pushyErr, err)
+	case "topic list":
+		if len(args) != 0 {
+			return usageError("usage: pushy topic list")
+		}
+		lister, ok := c.sdk.(pushy.ITopicsClient)
+		if !ok {
+			return errors.New("listing topics isn't supported by this client")
+		}
+		res, pushyErr, err := lister.Topics()
+		return c.print(res, pushyErr, err)
+	}
+	return usageError(fmt.Sprintf("unknown command %q", command+" "+sub))
+}
+
+// print writes result of a call, in dry run mode nothing was sent so there's nothing to print
+func (c cli) print(result interface{}, pushyErr *pushy.Error, err error) error {
+	if c.dryRun && errors.Is(err, errDryRun) {
+		return nil
+	}
+	if pushyErr != nil {
+		return fmt.Errorf("pushy: %s (%v)", pushyErr.Error, err)
+	}
+	if err != nil {
+		return err
+	}
+	return write(c.stdout, c.output, result)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func execute(args []string, environment map[string]string, stdin string) result {
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string {
+		return environment[key]
+	}
+	code := run(args, getenv, strings.NewReader(stdin), &stdout, &stderr)
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+// fakePushy starts a fake pushy which accepts api key TOKEN
+func fakePushy() *pushytest.Server {
+	server := pushytest.NewServer()
+	server.SetAPIKey("TOKEN")
+	return server
+}
+
+func TestRun_Commands(t *testing.T) {
+	server := fakePushy()
+	defer server.Close()
+	environment := map[string]string{"PUSHY_API_TOKEN": "TOKEN", "PUSHY_API_ENDPOINT": server.URL}
+	table := []struct {
+		args     []string
+		contains []string
+	}{
+		{args: []string{"device", "info", "DEVICE"}, contains: []string{"android", "2015-10-18T22:29:18Z", "news"}},
+		{args: []string{"device", "presence", "DEVICE"}, contains: []string{"ID", "DEVICE", "false"}},
+		{args: []string{"push", "status", "PUSH_ID"}, contains: []string{"2016-05-23T11:45:35Z", "DEVICE"}},
+		{args: []string{"push", "delete", "PUSH_ID"}, contains: []string{"true"}},
+		{args: []string{"topic", "subscribe", "DEVICE", "news"}, contains: []string{"true"}},
+		{args: []string{"topic", "unsubscribe", "DEVICE", "news"}, contains: []string{"true"}},
+		{args: []string{"topic", "list"}, contains: []string{"NAME", "news", "3"}},
+		{args: []string{"send", "-to", "DEVICE", "-data", `{"message":"hi"}`}, contains: []string{"PUSH1"}},
+		{args: []string{"-output", "json", "topic", "list"}, contains: []string{`"name": "news"`}},
+	}
+	for _, data := range table {
+		res := execute(data.args, environment, "")
+		assert.Equal(t, 0, res.code, res.stderr)
+		for _, expected := range data.contains {
+			assert.Contains(t, res.stdout, expected, strings.Join(data.args, " "))
+		}
+	}
+	requests := server.Requests()
+	for _, request := range requests {
+		assert.NotEqual(t, http.StatusNotFound, request.Status, "unexpected request %s", request)
+	}
+	assert.Contains(t, requests[len(requests)-2].String(), `"message\":\"hi\"`)
+}
+
+func TestRun_SendFromStdin(t *testing.T) {
+	server := fakePushy()
+	defer server.Close()
+	res := execute([]string{"-token", "TOKEN", "-endpoint", server.URL, "send", "-file", "-", "-to", "SECOND"}, nil, `{"to":["FIRST"],"notification":{"title":"hello"}}`)
+	assert.Equal(t, 0, res.code, res.stderr)
+	sent := server.Requests()[0].String()
+	assert.Contains(t, sent, `"to":["FIRST","SECOND"]`)
+	assert.Contains(t, sent, `"title":"hello"`)
+}
+
+func TestRun_DryRun(t *testing.T) {
+	Assert := assert.New(t)
+	res := execute([]string{"-dry-run", "-token", "SECRET", "send", "-to", "DEVICE", "-title", "hi"}, nil, "")
+	Assert.Equal(0, res.code, res.stderr)
+	Assert.Contains(res.stdout, "POST https://api.pushy.me/push?api_key=REDACTED")
+	Assert.Contains(res.stdout, `"title": "hi"`)
+	Assert.NotContains(res.stdout, "SECRET")
+
+	res = execute([]string{"-dry-run", "push", "delete", "PUSH_ID"}, nil, "")
+	Assert.Equal(0, res.code, res.stderr)
+	Assert.Equal("DELETE https://api.pushy.me/pushes/PUSH_ID?api_key=REDACTED\n", res.stdout)
+}
+
+func TestRun_Config(t *testing.T) {
+	server := fakePushy()
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "pushy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(path, []byte(`{"api_token":"TOKEN","api_endpoint":"`+server.URL+`"}`), 0600)
+
+	res := execute([]string{"-config", path, "topic", "list"}, nil, "")
+	assert.Equal(t, 0, res.code, res.stderr)
+
+	res = execute([]string{"topic", "list"}, map[string]string{"PUSHY_CONFIG": path, "PUSHY_API_TOKEN": "WRONG"}, "")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "invalid api key")
+
+	res = execute([]string{"-config", filepath.Join(dir, "missing.json"), "topic", "list"}, nil, "")
+	assert.Equal(t, 1, res.code)
+}
+
+func TestRun_Usage(t *testing.T) {
+	table := []struct {
+		args []string
+		code int
+	}{
+		{args: []string{}, code: 2},
+		{args: []string{"-output", "xml", "topic", "list"}, code: 2},
+		{args: []string{"-token", "T", "device"}, code: 2},
+		{args: []string{"-token", "T", "device", "reboot"}, code: 2},
+		{args: []string{"-token", "T", "device", "info"}, code: 2},
+		{args: []string{"-token", "T", "send", "-unknown"}, code: 2},
+		{args: []string{"-token", "T", "send"}, code: 1},
+		{args: []string{"topic", "list"}, code: 1},
+	}
+	for _, data := range table {
+		res := execute(data.args, nil, "")
+		assert.Equal(t, data.code, res.code, strings.Join(data.args, " "))
+		assert.NotEmpty(t, res.stderr)
+	}
+}
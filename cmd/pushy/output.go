@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fossapps/pushy"
+)
+
+func write(out io.Writer, format string, result interface{}) error {
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	switch res := result.(type) {
+	case *pushy.DeviceInfo:
+		fmt.Fprintf(table, "platform\t%s\n", res.Device.Platform)
+		fmt.Fprintf(table, "registered\t%s\n", formatTime(res.Device.Date))
+		fmt.Fprintf(table, "online\t%t\n", res.Presence.Online)
+		fmt.Fprintf(table, "last active\t%s (%ds ago)\n", formatTime(res.Presence.LastActive.Date), res.Presence.LastActive.SecondsAgo)
+		fmt.Fprintf(table, "subscriptions\t%s\n", strings.Join(res.Subscriptions, ", "))
+		fmt.Fprintf(table, "pending notifications\t%d\n", len(res.PendingNotifications))
+	case *pushy.DevicePresenceResponse:
+		fmt.Fprintln(table, "ID\tONLINE\tLAST ACTIVE")
+		for _, presence := range res.Presence {
+			fmt.Fprintf(table, "%s\t%t\t%s\n", presence.ID, presence.Online, formatTime(presence.LastActive))
+		}
+	case *pushy.NotificationStatus:
+		fmt.Fprintf(table, "date\t%s\n", formatTime(res.Push.Date))
+		fmt.Fprintf(table, "expiration\t%s\n", formatTime(res.Push.Expiration))
+		fmt.Fprintf(table, "pending devices\t%s\n", strings.Join(res.Push.PendingDevices, ", "))
+	case *pushy.NotificationResponse:
+		fmt.Fprintf(table, "success\t%t\n", res.Success)
+		fmt.Fprintf(table, "id\t%s\n", res.ID)
+		if res.Info != nil {
+			fmt.Fprintf(table, "devices\t%d\n", res.Info.Devices)
+			fmt.Fprintf(table, "failed\t%s\n", strings.Join(res.Info.Failed, ", "))
+		}
+	case *pushy.SimpleSuccess:
+		fmt.Fprintf(table, "success\t%t\n", res.Success)
+	case *pushy.TopicsResponse:
+		fmt.Fprintln(table, "NAME\tSUBSCRIBERS")
+		for _, topic := range res.Topics {
+			fmt.Fprintf(table, "%s\t%s\n", topic.Name, strconv.Itoa(topic.Subscribers))
+		}
+	default:
+		return fmt.Errorf("can't print %T as table", result)
+	}
+	return table.Flush()
+}
+
+func formatTime(t pushy.UnixTime) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
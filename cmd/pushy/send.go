@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/fossapps/pushy"
+)
+
+// stringList is a flag which can be repeated or given comma separated values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// send builds request from -file (or stdin with -file -) and then applies flags on top of it
+func (c cli) send(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var to stringList
+	flags.Var(&to, "to", "device token or /topics/name, can be repeated or comma separated")
+	file := flags.String("file", "", "json SendNotificationRequest to start from, - reads stdin")
+	data := flags.String("data", "", "data payload (json)")
+	ttl := flags.Int("ttl", 0, "time to live in seconds")
+	title := flags.String("title", "", "iOS notification title")
+	body := flags.String("body", "", "iOS notification body")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if flags.NArg() != 0 {
+		return usageError(fmt.Sprintf("send: unexpected arguments %v", flags.Args()))
+	}
+	var request pushy.SendNotificationRequest
+	if *file != "" {
+		if err := c.readRequest(*file, &request); err != nil {
+			return err
+		}
+	}
+	request.To = append(request.To, to...)
+	if *data != "" {
+		request.Data = *data
+	}
+	if *ttl != 0 {
+		request.TimeToLive = *ttl
+	}
+	if *title != "" {
+		request.IOSNotification.Title = *title
+	}
+	if *body != "" {
+		request.IOSNotification.Body = *body
+	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	res, pushyErr, err := c.sdk.NotifyDevice(request)
+	return c.print(res, pushyErr, err)
+}
+
+func (c cli) readRequest(file string, request *pushy.SendNotificationRequest) error {
+	var reader io.Reader = c.stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		reader = f
+	}
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, request); err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	return nil
+}
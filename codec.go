@@ -0,0 +1,43 @@
+package pushy
+
+import "encoding/json"
+
+// Codec encodes requests and decodes responses, it can be used to plug in a faster json library.
+// whatever it is, it has to produce and understand the same json as encoding/json including the
+// json.Marshaler/Unmarshaler implementations of types like UnixTime, codectest.Run checks that
+// and every codec is expected to pass it.
+// it's used from multiple goroutines at once so it has to be safe for that
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is the default Codec using encoding/json
+type JSONCodec struct{}
+
+// Marshal calls json.Marshal
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal calls json.Unmarshal
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// SetCodec changes codec used for requests which start after it returns, nil restores JSONCodec
+func (p *Pushy) SetCodec(codec Codec) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codec = codec
+}
+
+// GetCodec returns codec used by pushy
+func (p *Pushy) GetCodec() Codec {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.codec == nil {
+		return JSONCodec{}
+	}
+	return p.codec
+}
@@ -0,0 +1,86 @@
+package pushy_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/codectest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONCodec(t *testing.T) {
+	codectest.Run(t, pushy.JSONCodec{})
+}
+
+// countingCodec counts calls of JSONCodec
+type countingCodec struct {
+	pushy.JSONCodec
+	marshaled   int32
+	unmarshaled int32
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&c.marshaled, 1)
+	return c.JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&c.unmarshaled, 1)
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func TestCountingCodec(t *testing.T) {
+	codectest.Run(t, &countingCodec{})
+}
+
+type failingCodec struct{}
+
+func (failingCodec) Marshal(v interface{}) ([]byte, error) {
+	return nil, errors.New("can't marshal")
+}
+
+func (failingCodec) Unmarshal(data []byte, v interface{}) error {
+	return errors.New("can't unmarshal")
+}
+
+func TestPushy_SetCodec(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"id":"PUSH"}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	Assert.Equal(pushy.JSONCodec{}, sdk.GetCodec())
+
+	codec := &countingCodec{}
+	sdk.SetCodec(codec)
+	res, _, err := sdk.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Equal("PUSH", res.ID)
+	_, _, err = sdk.DeleteNotification("PUSH")
+	Assert.Nil(err)
+	Assert.Equal(int32(1), codec.marshaled)
+	Assert.Equal(int32(2), codec.unmarshaled)
+
+	// streamed requests are encoded with codec as well
+	sdk.SetStreamingThreshold(1)
+	_, _, err = sdk.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Equal(int32(2), codec.marshaled)
+
+	sdk.SetStreamingThreshold(0)
+	sdk.SetCodec(failingCodec{})
+	_, _, err = sdk.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.EqualError(err, "can't marshal")
+	_, _, err = sdk.Topics()
+	Assert.EqualError(err, "can't unmarshal")
+
+	sdk.SetCodec(nil)
+	Assert.Equal(pushy.JSONCodec{}, sdk.GetCodec())
+}
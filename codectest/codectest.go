@@ -0,0 +1,227 @@
+// Package codectest is the conformance suite for pushy.Codec implementations,
+// a codec is compatible with pushy when it passes Run
+//  func TestCodec(t *testing.T) {
+//  	codectest.Run(t, MyCodec{})
+//  }
+package codectest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+)
+
+// responses recorded from pushy, along with what they decode to
+var responses = []struct {
+	name     string
+	body     string
+	into     func() interface{}
+	expected interface{}
+}{
+	{
+		name: "device info",
+		body: `{
+  "device": {"date": 1445207358, "platform": "android"},
+  "subscriptions": ["news", "media"],
+  "presence": {"online": true, "last_active": {"date": 1464006925, "seconds_ago": 215}},
+  "pending_notifications": [
+    {"id": "5742fe0407c3674e226892f9", "date": 1464008196, "payload": {"message": "Hello World!"}, "expiration": 1466600196}
+  ]
+}`,
+		into: func() interface{} { return &pushy.DeviceInfo{} },
+		expected: &pushy.DeviceInfo{
+			Device:        pushy.Device{Date: unix(1445207358), Platform: pushy.PlatformAndroid},
+			Subscriptions: []string{"news", "media"},
+			Presence: pushy.DevicePresenceInfo{
+				Online:     true,
+				LastActive: pushy.LastActive{Date: unix(1464006925), SecondsAgo: 215},
+			},
+			PendingNotifications: []pushy.Notification{{
+				ID:         "5742fe0407c3674e226892f9",
+				Date:       unix(1464008196),
+				Payload:    map[string]interface{}{"message": "Hello World!"},
+				Expiration: unix(1466600196),
+			}},
+		},
+	},
+	{
+		name: "device presence",
+		body: `{"presence": [{"id": "a6f36efb913f1def30c6", "online": false, "last_active": 1429406442}]}`,
+		into: func() interface{} { return &pushy.DevicePresenceResponse{} },
+		expected: &pushy.DevicePresenceResponse{
+			Presence: []pushy.Presence{{ID: "a6f36efb913f1def30c6", LastActive: unix(1429406442)}},
+		},
+	},
+	{
+		name: "notification status",
+		body: `{
+  "push": {
+    "date": 1464003935,
+    "payload": {"message": "Hello World!"},
+    "expiration": 1466595935,
+    "pending_devices": ["fe8f7b2c102e883e5b41d2"]
+  }
+}`,
+		into: func() interface{} { return &pushy.NotificationStatus{} },
+		expected: &pushy.NotificationStatus{Push: pushy.PushStatus{
+			Date:           unix(1464003935),
+			Payload:        map[string]interface{}{"message": "Hello World!"},
+			Expiration:     unix(1466595935),
+			PendingDevices: []string{"fe8f7b2c102e883e5b41d2"},
+		}},
+	},
+	{
+		name: "notification response",
+		body: `{"success": true, "id": "5742fe0407c3674e226892f9", "info": {"devices": 1, "failed": ["a6345d0278adc55d3474f5"]}}`,
+		into: func() interface{} { return &pushy.NotificationResponse{} },
+		expected: &pushy.NotificationResponse{
+			Success: true,
+			ID:      "5742fe0407c3674e226892f9",
+			Info:    &pushy.NotificationInfo{Devices: 1, Failed: []string{"a6345d0278adc55d3474f5"}},
+		},
+	},
+	{
+		name:     "simple success",
+		body:     `{"success": true}`,
+		into:     func() interface{} { return &pushy.SimpleSuccess{} },
+		expected: &pushy.SimpleSuccess{Success: true},
+	},
+	{
+		name:     "topics",
+		body:     `{"topics": [{"name": "news", "subscribers": 3}, {"name": "media", "subscribers": 1}]}`,
+		into:     func() interface{} { return &pushy.TopicsResponse{} },
+		expected: &pushy.TopicsResponse{Topics: []pushy.Topic{{Name: "news", Subscribers: 3}, {Name: "media", Subscribers: 1}}},
+	},
+	{
+		name:     "error",
+		body:     `{"error": "not found / bad token"}`,
+		into:     func() interface{} { return &pushy.Error{} },
+		expected: &pushy.Error{Error: "not found / bad token"},
+	},
+	{
+		name:     "missing dates",
+		body:     `{"device": {"date": null, "platform": "ios"}, "presence": {"last_active": {"date": 0}}}`,
+		into:     func() interface{} { return &pushy.DeviceInfo{} },
+		expected: &pushy.DeviceInfo{Device: pushy.Device{Platform: pushy.PlatformIOS}},
+	},
+	{
+		name:     "unknown fields",
+		body:     `{"success": true, "id": "ID", "region": {"name": "eu"}}`,
+		into:     func() interface{} { return &pushy.NotificationResponse{} },
+		expected: &pushy.NotificationResponse{Success: true, ID: "ID"},
+	},
+}
+
+// requests along with the json pushy expects for them
+var requests = []struct {
+	name     string
+	request  interface{}
+	expected string
+}{
+	{
+		name: "notification",
+		request: pushy.SendNotificationRequest{
+			To:                []string{"a6345d0278adc55d3474f5", "/topics/news"},
+			Data:              `{"message":"Hello \"World\" <&>"}`,
+			TimeToLive:        3600,
+			IOSMutableContent: true,
+			IOSNotification: pushy.IOSNotification{
+				Body:    "Hello World",
+				Badge:   1,
+				Sound:   "ping.aiff",
+				LocKey:  "GREETING",
+				LocArgs: []string{"Jenna"},
+			},
+			AndroidOptions: pushy.AndroidOptions{Priority: pushy.AndroidPriorityHigh, ChannelID: "news"},
+		},
+		expected: `{
+  "to": ["a6345d0278adc55d3474f5", "/topics/news"],
+  "data": "{\"message\":\"Hello \\\"World\\\" <&>\"}",
+  "time_to_live": 3600,
+  "mutable_content": true,
+  "content_available": false,
+  "notification": {
+    "body": "Hello World", "badge": 1, "sound": "ping.aiff", "title": "", "category": "",
+    "loc_key": "GREETING", "loc_args": ["Jenna"], "title_loc_key": "", "title_loc_args": null
+  },
+  "priority": "high",
+  "channel_id": "news"
+}`,
+	},
+	{
+		name:     "minimal notification",
+		request:  pushy.SendNotificationRequest{To: []string{"a6345d0278adc55d3474f5"}},
+		expected: `{"to": ["a6345d0278adc55d3474f5"], "data": "", "time_to_live": 0, "mutable_content": false, "content_available": false, "notification": {"body": "", "badge": 0, "sound": "", "title": "", "category": "", "loc_key": "", "loc_args": null, "title_loc_key": "", "title_loc_args": null}}`,
+	},
+	{
+		name:     "device presence",
+		request:  pushy.DevicePresenceRequest{Tokens: []string{"a6345d0278adc55d3474f5", "fe8f7b2c102e883e5b41d2"}},
+		expected: `{"tokens": ["a6345d0278adc55d3474f5", "fe8f7b2c102e883e5b41d2"]}`,
+	},
+	{
+		name:     "topic subscription",
+		request:  pushy.DeviceSubscriptionRequest{Token: "a6345d0278adc55d3474f5", Topics: []string{"news", "media"}},
+		expected: `{"token": "a6345d0278adc55d3474f5", "topics": ["news", "media"]}`,
+	},
+	{
+		name:     "dates",
+		request:  pushy.PushStatus{Date: unix(1464003935), PendingDevices: []string{}},
+		expected: `{"date": 1464003935, "payload": null, "expiration": 0, "pending_devices": []}`,
+	},
+}
+
+func unix(seconds int64) pushy.UnixTime {
+	return pushy.UnixTime{Time: time.Unix(seconds, 0)}
+}
+
+// Run checks that codec decodes recorded pushy responses and encodes requests the way pushy expects
+func Run(t *testing.T, codec pushy.Codec) {
+	for _, response := range responses {
+		response := response
+		t.Run("decode "+response.name, func(t *testing.T) {
+			v := response.into()
+			if err := codec.Unmarshal([]byte(response.body), v); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(normalize(v), normalize(response.expected)) {
+				t.Errorf("decoded %+v\nexpected %+v", v, response.expected)
+			}
+		})
+	}
+	for _, request := range requests {
+		request := request
+		t.Run("encode "+request.name, func(t *testing.T) {
+			encoded, err := codec.Marshal(request.request)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var got, expected interface{}
+			if err := json.Unmarshal(encoded, &got); err != nil {
+				t.Fatalf("codec produced invalid json %s: %v", encoded, err)
+			}
+			if err := json.Unmarshal([]byte(request.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("encoded %s\nexpected %s", encoded, request.expected)
+			}
+		})
+	}
+	t.Run("invalid json", func(t *testing.T) {
+		if err := codec.Unmarshal([]byte(`{"success": tru`), &pushy.SimpleSuccess{}); err == nil {
+			t.Error("Unmarshal should fail on invalid json")
+		}
+	})
+}
+
+// normalize decodes v through encoding/json, so decoded values are compared by what they mean,
+// times are equal when they're the same second no matter their location
+func normalize(v interface{}) interface{} {
+	encoded, _ := json.Marshal(v)
+	var normalized interface{}
+	json.Unmarshal(encoded, &normalized)
+	return normalized
+}
@@ -0,0 +1,77 @@
+package pushy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// gzipWriters holds writers used to compress requests, a writer is used only until it's closed
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// SetCompressionThreshold makes pushy gzip request bodies of at least bytes, streamed requests are always
+// compressed while it's enabled. 0 disables compression.
+// if endpoint responds with 415 Unsupported Media Type the request is sent again uncompressed
+// and this client stops compressing, so it's safe to enable for endpoints which might not accept it
+func (p *Pushy) SetCompressionThreshold(bytes int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.compressionThreshold = bytes
+	atomic.StoreInt32(&p.gzipRejected, 0)
+}
+
+// compressing tells if a body of size should be compressed, size < 0 is a streamed body
+func (r requester) compressing(size int) bool {
+	if r.compressionThreshold <= 0 || atomic.LoadInt32(r.gzipRejected) == 1 {
+		return false
+	}
+	return size < 0 || size >= r.compressionThreshold
+}
+
+// withoutCompression is used to send a request again after endpoint rejected a compressed one
+func (r requester) withoutCompression() requester {
+	atomic.StoreInt32(r.gzipRejected, 1)
+	r.compressionThreshold = 0
+	return r
+}
+
+// compress gzips encoded into a new buffer, the buffer isn't pooled since transport owns it once it's sent
+func compress(encoded []byte) (*bytes.Buffer, error) {
+	buffer := bytes.NewBuffer(make([]byte, 0, len(encoded)/4))
+	writer := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(writer)
+	writer.Reset(buffer)
+	if _, err := writer.Write(encoded); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+// compressStream gzips whatever encode writes
+func compressStream(w io.Writer, encode func(io.Writer) error) error {
+	writer := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(writer)
+	writer.Reset(w)
+	if err := encode(writer); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// responseBody returns body of response, decompressed if pushy (or a proxy) sent it gzipped.
+// net/http already does this when it asked for gzip itself, then the header is removed
+func responseBody(response *http.Response) (io.Reader, error) {
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return response.Body, nil
+	}
+	return gzip.NewReader(response.Body)
+}
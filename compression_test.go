@@ -0,0 +1,137 @@
+package pushy_test
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// pushed returns encodings and recipients of notifications server accepted
+func pushed(server *pushytest.Server) (encodings []string, to [][]string) {
+	for _, push := range server.Pushes() {
+		var request pushy.SendNotificationRequest
+		push.Decode(&request)
+		encodings = append(encodings, push.Encoding)
+		to = append(to, request.To)
+	}
+	return encodings, to
+}
+
+func TestCompression(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	sdk := clientFor(server)
+	sdk.SetCompressionThreshold(1024)
+
+	small := campaign(1)
+	large := campaign(1000)
+	for _, request := range []pushy.SendNotificationRequest{small, large} {
+		res, pushyErr, err := sdk.NotifyDevice(request)
+		Assert.Nil(err)
+		Assert.Nil(pushyErr)
+		Assert.NotEmpty(res.ID)
+	}
+	encodings, to := pushed(server)
+	Assert.Equal([]string{"", "gzip"}, encodings)
+	Assert.Equal(large.To, to[1])
+
+	// streamed requests are compressed regardless of size
+	sdk.SetStreamingThreshold(1)
+	_, _, err := sdk.NotifyDevice(small)
+	Assert.Nil(err)
+	encodings, to = pushed(server)
+	Assert.Equal("gzip", encodings[2])
+	Assert.Equal(small.To, to[2])
+
+	sdk.SetCompressionThreshold(0)
+	_, _, err = sdk.NotifyDevice(large)
+	Assert.Nil(err)
+	encodings, _ = pushed(server)
+	Assert.Equal("", encodings[3])
+}
+
+func TestCompression_Rejected(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.RejectGzip(true)
+	sdk := clientFor(server)
+	sdk.SetCompressionThreshold(1)
+
+	_, _, err := sdk.NotifyDevice(campaign(10))
+	Assert.Nil(err, "rejected request is sent again uncompressed")
+	_, _, err = sdk.NotifyDevice(campaign(10))
+	Assert.Nil(err)
+	encodings, _ := pushed(server)
+	Assert.Equal([]string{"", ""}, encodings)
+
+	// streamed requests fall back as well
+	sdk.SetCompressionThreshold(1)
+	sdk.SetStreamingThreshold(1)
+	res, _, err := sdk.NotifyDevice(campaign(10))
+	Assert.Nil(err)
+	Assert.Equal("PUSH3", res.ID)
+	encodings, to := pushed(server)
+	Assert.Equal([]string{"", "", ""}, encodings)
+	Assert.Equal(campaign(10).To, to[2])
+}
+
+func TestCompression_GzipResponse(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		defer writer.Close()
+		if r.URL.Path != "/topics" {
+			w.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		writer.Write([]byte(`{"topics":[{"name":"news","subscribers":3}]}`))
+	}))
+	defer server.Close()
+
+	// without it net/http would decompress the response itself
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}, Timeout: time.Second}
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(client)
+	topics, _, err := sdk.Topics()
+	Assert.Nil(err)
+	Assert.Equal([]pushy.Topic{{Name: "news", Subscribers: 3}}, topics.Topics)
+	_, pushyErr, err := sdk.DeviceInfo("D")
+	Assert.Contains(err.Error(), "404")
+	Assert.Equal("not found", pushyErr.Error)
+
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	topics, _, err = sdk.Topics()
+	Assert.Nil(err)
+	Assert.Equal("news", topics.Topics[0].Name)
+}
+
+func BenchmarkCompression(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		w.Write([]byte(`{"success":true,"id":"PUSH"}`))
+	}))
+	defer server.Close()
+	request := campaign(10000)
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Second))
+	sdk.SetCompressionThreshold(1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := sdk.NotifyDevice(request); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
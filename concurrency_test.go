@@ -0,0 +1,85 @@
+package pushy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPushy_Concurrent shares one client between goroutines making requests and changing settings,
+// it's meant to be run with -race
+func TestPushy_Concurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/devices/presence":
+			w.Write([]byte(`{"presence":[]}`))
+		case "/topics":
+			w.Write([]byte(`{"topics":[]}`))
+		default:
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+	client := pushy.Create("SECRET", server.URL)
+	client.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+
+	calls := []func() error{
+		func() error { _, _, err := client.DeviceInfo("D"); return err },
+		func() error { _, _, err := client.DevicePresence("D"); return err },
+		func() error { _, _, err := client.NotificationStatus("P"); return err },
+		func() error { _, _, err := client.DeleteNotification("P"); return err },
+		func() error { _, _, err := client.SubscribeToTopic("D", "t"); return err },
+		func() error { _, _, err := client.UnsubscribeFromTopic("D", "t"); return err },
+		func() error { _, _, err := client.Topics(); return err },
+		func() error {
+			_, _, err := client.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+			return err
+		},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		for _, call := range calls {
+			wg.Add(1)
+			go func(call func() error) {
+				defer wg.Done()
+				assert.Nil(t, call())
+			}(call)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+			client.SetAPIToken("SECRET")
+			client.SetAPIEndpoint(server.URL)
+			client.SetCredentialProvider(nil)
+			client.GetHTTPClient()
+			client.GetCredentialProvider()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPushy_SettersAffectNextRequest(t *testing.T) {
+	Assert := assert.New(t)
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.URL.Query().Get("api_key"))
+		w.Write([]byte(`{"topics":[]}`))
+	}))
+	defer server.Close()
+	client := pushy.Create("OLD", "http://127.0.0.1:1")
+	client.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	client.SetAPIEndpoint(server.URL)
+	client.Topics()
+	client.SetAPIToken("NEW")
+	client.Topics()
+	Assert.Equal([]string{"OLD", "NEW"}, tokens)
+	Assert.Equal("NEW", client.APIToken)
+}
@@ -0,0 +1,190 @@
+package pushy
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider provides the api key, it's asked for the key on every request
+// so implementations need to be safe to use from multiple goroutines
+type CredentialProvider interface {
+	APIToken() (string, error)
+}
+
+// CredentialRefresher can be implemented by a CredentialProvider which is able to fetch a new key,
+// when pushy rejects a key with 401 Refresh is called and request is retried once, if the key changed
+type CredentialRefresher interface {
+	Refresh() error
+}
+
+// StaticCredentials always provides the same token
+type StaticCredentials string
+
+// APIToken returns token
+func (c StaticCredentials) APIToken() (string, error) {
+	return string(c), nil
+}
+
+// EnvCredentials reads token from environment variable with this name on every request
+type EnvCredentials string
+
+// APIToken returns value of environment variable, it's an error if it's empty
+func (c EnvCredentials) APIToken() (string, error) {
+	token := os.Getenv(string(c))
+	if token == "" {
+		return "", fmt.Errorf("pushy: environment variable %s is empty", string(c))
+	}
+	return token, nil
+}
+
+// Refresh doesn't need to do anything, variable is read again and request is retried if it changed
+func (c EnvCredentials) Refresh() error {
+	return nil
+}
+
+// CredentialFunc is called on every request to get the token
+type CredentialFunc func() (string, error)
+
+// APIToken calls f
+func (f CredentialFunc) APIToken() (string, error) {
+	return f()
+}
+
+// Refresh doesn't need to do anything, f is called again and request is retried if it returns another token
+func (f CredentialFunc) Refresh() error {
+	return nil
+}
+
+// FileCredentials reads token from a file (like a mounted secret) and reloads it when the file changes
+type FileCredentials struct {
+	path     string
+	interval time.Duration
+
+	mu           sync.Mutex
+	token        string
+	modified     time.Time
+	lastChecked  time.Time
+	lastReadFail error
+}
+
+// NewFileCredentials reads token from path, file is checked for changes at most once every interval
+func NewFileCredentials(path string, interval time.Duration) (*FileCredentials, error) {
+	c := &FileCredentials{path: path, interval: interval}
+	if err := c.Refresh(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// APIToken returns the token, reloading file if it changed since last check
+func (c *FileCredentials) APIToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.lastChecked) >= c.interval {
+		c.lastChecked = time.Now()
+		if info, err := os.Stat(c.path); err == nil && !info.ModTime().Equal(c.modified) {
+			c.read()
+		}
+	}
+	if c.token == "" {
+		return "", c.lastReadFail
+	}
+	return c.token, nil
+}
+
+// Refresh reads the file again
+func (c *FileCredentials) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastChecked = time.Now()
+	return c.read()
+}
+
+// read keeps the previous token if file can't be read, so a half written file doesn't break requests
+func (c *FileCredentials) read() error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		c.lastReadFail = err
+		return err
+	}
+	content, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		c.lastReadFail = err
+		return err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		c.lastReadFail = fmt.Errorf("pushy: %s is empty", c.path)
+		return c.lastReadFail
+	}
+	c.token = token
+	c.modified = info.ModTime()
+	c.lastReadFail = nil
+	return nil
+}
+
+// SetCredentialProvider makes pushy ask provider for api key on every request instead of using APIToken
+func (p *Pushy) SetCredentialProvider(provider CredentialProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.credentials = provider
+}
+
+// GetCredentialProvider returns provider set with SetCredentialProvider
+func (p *Pushy) GetCredentialProvider() CredentialProvider {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.credentials
+}
+
+// withCredentials calls request with http client, codec and url of path including api key,
+// if pushy rejects the key and credentials can be refreshed to a different key request is retried once with it.
+// settings are read once, so a request isn't affected by setters called while it's running
+func (p *Pushy) withCredentials(path string, request func(r requester, url string) error) error {
+	p.mu.RLock()
+	endpoint, token, credentials := p.APIEndpoint, p.APIToken, p.credentials
+	r := requester{
+		client:               p.httpClient,
+		codec:                p.codec,
+		compressionThreshold: p.compressionThreshold,
+		gzipRejected:         &p.gzipRejected,
+		streamingThreshold:   p.streamingThreshold,
+	}
+	p.mu.RUnlock()
+	if r.codec == nil {
+		r.codec = JSONCodec{}
+	}
+	if credentials == nil {
+		return request(r, apiURL(endpoint, path, token))
+	}
+	token, err := credentials.APIToken()
+	if err != nil {
+		return err
+	}
+	err = request(r, apiURL(endpoint, path, token))
+	var httpErr StatusError
+	if !errors.As(err, &httpErr) || httpErr.Code != 401 {
+		return err
+	}
+	refresher, ok := credentials.(CredentialRefresher)
+	if !ok || refresher.Refresh() != nil {
+		return err
+	}
+	// the same key would only be rejected again
+	refreshed, tokenErr := credentials.APIToken()
+	if tokenErr != nil || refreshed == token {
+		return err
+	}
+	return request(r, apiURL(endpoint, path, refreshed))
+}
+
+// apiURL is url of path with api key as query parameter
+func apiURL(endpoint string, path string, token string) string {
+	return fmt.Sprintf("%s%s?api_key=%s", endpoint, path, url.QueryEscape(token))
+}
@@ -0,0 +1,194 @@
+package pushy_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// apiKeys returns api keys of every request server received
+func apiKeys(server *pushytest.Server) []string {
+	var keys []string
+	for _, request := range server.Requests() {
+		keys = append(keys, request.APIKey)
+	}
+	return keys
+}
+
+func newKeyClient(server *pushytest.Server, provider pushy.CredentialProvider) *pushy.Pushy {
+	client := pushy.Create("", server.URL)
+	client.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	client.SetCredentialProvider(provider)
+	return client
+}
+
+func TestCredentials_RetryAfterRefresh(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("NEW")
+	credentials := &pushytest.Credentials{Tokens: []string{"OLD", "NEW"}}
+	client := newKeyClient(server, credentials)
+
+	topics, pushyErr, err := client.Topics()
+	Assert.Nil(err)
+	Assert.Nil(pushyErr)
+	Assert.NotNil(topics)
+	Assert.Equal(1, credentials.Refreshed())
+	Assert.Equal([]string{"OLD", "NEW"}, apiKeys(server))
+	Assert.Equal(credentials, client.GetCredentialProvider())
+}
+
+func TestCredentials_RetriesOnlyOnce(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("SECRET")
+	credentials := &pushytest.Credentials{Tokens: []string{"OLD", "WRONG", "SECRET"}}
+	client := newKeyClient(server, credentials)
+
+	_, pushyErr, err := client.Topics()
+	Assert.Contains(err.Error(), "401")
+	Assert.Equal("invalid api key", pushyErr.Error)
+	Assert.Equal([]string{"OLD", "WRONG"}, apiKeys(server))
+
+	// a key which didn't change isn't sent again
+	server.Reset()
+	client = newKeyClient(server, pushy.CredentialFunc(func() (string, error) { return "WRONG", nil }))
+	_, _, err = client.Topics()
+	Assert.NotNil(err)
+	Assert.Equal([]string{"WRONG"}, apiKeys(server), "refresh didn't change the key")
+
+	server.Reset()
+	client = newKeyClient(server, pushy.StaticCredentials("WRONG"))
+	_, _, err = client.Topics()
+	Assert.NotNil(err)
+	Assert.Equal([]string{"WRONG"}, apiKeys(server), "static credentials can't be refreshed")
+}
+
+func TestCredentials_ProviderError(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("SECRET")
+	failure := errors.New("vault is sealed")
+	client := newKeyClient(server, pushy.CredentialFunc(func() (string, error) { return "", failure }))
+
+	topics, pushyErr, err := client.Topics()
+	Assert.Nil(topics)
+	Assert.Nil(pushyErr)
+	Assert.Equal(failure, err)
+	Assert.Empty(apiKeys(server))
+}
+
+func TestEnvCredentials(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("SECRET")
+	os.Setenv("PUSHY_TEST_TOKEN", "SECRET")
+	defer os.Unsetenv("PUSHY_TEST_TOKEN")
+	client := newKeyClient(server, pushy.EnvCredentials("PUSHY_TEST_TOKEN"))
+
+	_, _, err := client.Topics()
+	Assert.Nil(err)
+
+	os.Setenv("PUSHY_TEST_TOKEN", "")
+	_, _, err = client.Topics()
+	Assert.Contains(err.Error(), "PUSHY_TEST_TOKEN")
+}
+
+func TestCredentials_EscapesKey(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("a&b=c+d e")
+
+	client := newKeyClient(server, pushy.StaticCredentials("a&b=c+d e"))
+	_, _, err := client.Topics()
+	Assert.Nil(err)
+	sdk := pushy.Create("a&b=c+d e", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	_, _, err = sdk.DeviceInfo("DEVICE")
+	Assert.Nil(err)
+	Assert.Equal([]string{"a&b=c+d e", "a&b=c+d e"}, apiKeys(server))
+}
+
+func TestFileCredentials(t *testing.T) {
+	Assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+
+	_, err = pushy.NewFileCredentials(path, time.Hour)
+	Assert.True(os.IsNotExist(err))
+
+	ioutil.WriteFile(path, []byte("OLD\n"), 0600)
+	credentials, err := pushy.NewFileCredentials(path, time.Hour)
+	Assert.Nil(err)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("OLD")
+	client := newKeyClient(server, credentials)
+	_, _, err = client.Topics()
+	Assert.Nil(err)
+
+	// key is rotated before the interval passes, 401 makes client read the file again
+	ioutil.WriteFile(path, []byte("NEW"), 0600)
+	server.SetAPIKey("NEW")
+	_, _, err = client.Topics()
+	Assert.Nil(err)
+	Assert.Equal([]string{"OLD", "OLD", "NEW"}, apiKeys(server))
+
+	// a broken file doesn't replace the token which works
+	ioutil.WriteFile(path, []byte(""), 0600)
+	Assert.NotNil(credentials.Refresh())
+	token, err := credentials.APIToken()
+	Assert.Nil(err)
+	Assert.Equal("NEW", token)
+}
+
+func TestFileCredentials_ReloadsChangedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+	ioutil.WriteFile(path, []byte("OLD"), 0600)
+	credentials, _ := pushy.NewFileCredentials(path, 0)
+
+	ioutil.WriteFile(path, []byte("NEW"), 0600)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	token, err := credentials.APIToken()
+	assert.Nil(t, err)
+	assert.Equal(t, "NEW", token)
+}
+
+func TestCredentials_Concurrent(t *testing.T) {
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetAPIKey("SECRET")
+	client := newKeyClient(server, pushy.CredentialFunc(func() (string, error) { return "SECRET", nil }))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := client.Topics()
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+}
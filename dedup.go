@@ -0,0 +1,221 @@
+package pushy
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaults of MemoryDedupStore used by NewDeduplicator
+const (
+	DefaultDedupCapacity = 10000
+	DefaultDedupTTL      = 24 * time.Hour
+)
+
+// DedupStore remembers responses of sent notifications by their idempotency key,
+// implement it to share deduplication between processes
+type DedupStore interface {
+	// Get returns response stored for key, nil when there's none or it expired
+	Get(key string) (*NotificationResponse, error)
+	// Set stores response for key
+	Set(key string, response *NotificationResponse) error
+}
+
+// DedupConfig configures a Deduplicator
+type DedupConfig struct {
+	// Store remembers sent notifications, by default a MemoryDedupStore
+	// of DefaultDedupCapacity keys which are kept for DefaultDedupTTL
+	Store DedupStore
+	// OnStoreError is called when a response couldn't be stored, the notification will be sent again
+	// if it's repeated. errors of Get are returned from NotifyDeviceWithOptions instead, without sending anything
+	OnStoreError func(key string, err error)
+}
+
+// Deduplicator is an IPushyClientWithOptions which sends a notification only once per idempotency key,
+// a repeated NotifyDeviceWithOptions with the same key returns the response of the first one instead of sending again
+//  client := pushy.NewDeduplicator(sdk, pushy.DedupConfig{})
+//  client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey(message.ID))
+// only successful sends are remembered, so a failed one can be retried with the same key.
+// calls without a key and the other operations go straight to the wrapped client.
+// it's safe to use from multiple goroutines, concurrent calls with the same key are sent once,
+// a call waiting for the one which sends stops waiting once its WithTimeout passes
+type Deduplicator struct {
+	IPushyClientWithOptions
+	config DedupConfig
+
+	mu       sync.Mutex
+	inFlight map[string]*dedupCall
+}
+
+// dedupCall is a send other calls with the same key wait for
+type dedupCall struct {
+	done     chan struct{}
+	response *NotificationResponse
+	pushyErr *Error
+	err      error
+}
+
+// NewDeduplicator wraps client, zero fields of config are defaults
+func NewDeduplicator(client IPushyClientWithOptions, config DedupConfig) *Deduplicator {
+	if config.Store == nil {
+		config.Store = NewMemoryDedupStore(DefaultDedupCapacity, DefaultDedupTTL)
+	}
+	return &Deduplicator{
+		IPushyClientWithOptions: client,
+		config:                  config,
+		inFlight:                map[string]*dedupCall{},
+	}
+}
+
+// NotifyDevice sends request, it doesn't have an idempotency key so it's never deduplicated
+func (d *Deduplicator) NotifyDevice(request SendNotificationRequest) (*NotificationResponse, *Error, error) {
+	return d.NotifyDeviceWithOptions(request)
+}
+
+// NotifyDeviceWithOptions sends request unless one with the same idempotency key was already sent
+func (d *Deduplicator) NotifyDeviceWithOptions(request SendNotificationRequest, opts ...CallOption) (*NotificationResponse, *Error, error) {
+	key := NewCallOptions(opts...).IdempotencyKey
+	if key == "" {
+		return d.IPushyClientWithOptions.NotifyDeviceWithOptions(request, opts...)
+	}
+	d.mu.Lock()
+	if call, ok := d.inFlight[key]; ok {
+		d.mu.Unlock()
+		return call.wait(NewCallOptions(opts...).Timeout)
+	}
+	call := &dedupCall{done: make(chan struct{})}
+	d.inFlight[key] = call
+	d.mu.Unlock()
+
+	call.response, call.pushyErr, call.err = d.send(key, request, opts)
+	d.mu.Lock()
+	delete(d.inFlight, key)
+	d.mu.Unlock()
+	close(call.done)
+	return copyResponse(call.response), call.pushyErr, call.err
+}
+
+// wait returns the outcome of call once it's done, or context.DeadlineExceeded when timeout passes first
+func (c *dedupCall) wait(timeout time.Duration) (*NotificationResponse, *Error, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-c.done:
+		return copyResponse(c.response), c.pushyErr, c.err
+	case <-expired:
+		return nil, nil, fmt.Errorf("pushy: waiting for the send with the same idempotency key: %w", context.DeadlineExceeded)
+	}
+}
+
+// copyResponse copies response along with its info, so callers sharing a response can't change it for each other
+func copyResponse(response *NotificationResponse) *NotificationResponse {
+	if response == nil {
+		return nil
+	}
+	copied := *response
+	if response.Info != nil {
+		info := *response.Info
+		info.Failed = append([]string(nil), info.Failed...)
+		copied.Info = &info
+	}
+	return &copied
+}
+
+func (d *Deduplicator) send(key string, request SendNotificationRequest, opts []CallOption) (*NotificationResponse, *Error, error) {
+	sent, err := d.config.Store.Get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sent != nil {
+		return sent, nil, nil
+	}
+	response, pushyErr, err := d.IPushyClientWithOptions.NotifyDeviceWithOptions(request, opts...)
+	if err != nil || response == nil {
+		return response, pushyErr, err
+	}
+	if err := d.config.Store.Set(key, response); err != nil && d.config.OnStoreError != nil {
+		d.config.OnStoreError(key, err)
+	}
+	return response, pushyErr, nil
+}
+
+// MemoryDedupStore keeps responses in memory, the least recently used ones are dropped when it's full
+// and every response expires ttl after it was stored. it's safe to use from multiple goroutines
+type MemoryDedupStore struct {
+	capacity int
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order has the most recently used entry in front
+	order *list.List
+}
+
+type dedupEntry struct {
+	key      string
+	response *NotificationResponse
+	expires  time.Time
+}
+
+// NewMemoryDedupStore creates a store of at most capacity keys kept for ttl,
+// capacity <= 0 doesn't limit the number of keys and ttl <= 0 keeps them until they're dropped
+func NewMemoryDedupStore(capacity int, ttl time.Duration) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get returns a copy of response stored for key
+func (s *MemoryDedupStore) Get(key string) (*NotificationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := element.Value.(*dedupEntry)
+	if s.ttl > 0 && !time.Now().Before(entry.expires) {
+		s.remove(element)
+		return nil, nil
+	}
+	s.order.MoveToFront(element)
+	return copyResponse(entry.response), nil
+}
+
+// Set stores a copy of response for key
+func (s *MemoryDedupStore) Set(key string, response *NotificationResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &dedupEntry{key: key, response: copyResponse(response), expires: time.Now().Add(s.ttl)}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.order.MoveToFront(element)
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(entry)
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of stored responses, expired ones included until they're looked up or dropped
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryDedupStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*dedupEntry).key)
+}
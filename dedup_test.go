@@ -0,0 +1,201 @@
+package pushy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ pushy.IPushyClientWithOptions = (*pushy.Deduplicator)(nil)
+
+func TestDeduplicator(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	client := pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{})
+	request := pushy.SendNotificationRequest{To: []string{"D"}}
+
+	first, _, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.Nil(err)
+	again, pushyErr, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.Nil(err)
+	Assert.Nil(pushyErr)
+	Assert.Equal(first, again)
+	Assert.Equal(1, len(server.Pushes()))
+
+	other, _, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("B"))
+	Assert.Nil(err)
+	Assert.Equal("PUSH2", other.ID)
+
+	// calls without a key are always sent
+	client.NotifyDevice(request)
+	client.NotifyDevice(request)
+	Assert.Equal(4, len(server.Pushes()))
+
+	// other operations go to the wrapped client
+	_, _, err = client.DeleteNotification("PUSH_ID")
+	Assert.Nil(err)
+}
+
+func TestDeduplicator_FailuresAreSentAgain(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	client := pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{})
+	request := pushy.SendNotificationRequest{To: []string{"D"}}
+
+	server.SetStatus(http.StatusBadGateway)
+	_, pushyErr, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.NotNil(err)
+	Assert.Equal("unavailable", pushyErr.Error)
+
+	server.SetStatus(0)
+	res, _, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.Nil(err)
+	Assert.Equal("PUSH1", res.ID)
+}
+
+func TestDeduplicator_Concurrent(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	release := server.Block()
+	client := pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{})
+
+	const callers = 10
+	ids := make(chan string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, _, err := client.NotifyDeviceWithOptions(pushy.SendNotificationRequest{To: []string{"D"}}, pushy.WithIdempotencyKey("A"))
+			if err == nil {
+				ids <- res.ID
+			}
+		}()
+	}
+	// give callers time to pile up on the first one
+	time.Sleep(50 * time.Millisecond)
+	release()
+	wg.Wait()
+	close(ids)
+	for id := range ids {
+		Assert.Equal("PUSH1", id)
+	}
+	Assert.Equal(1, len(server.Pushes()))
+}
+
+func TestDeduplicator_WaitTimeout(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	release := server.Block()
+	client := pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{})
+	request := pushy.SendNotificationRequest{To: []string{"D"}}
+
+	sent := make(chan *pushy.NotificationResponse)
+	go func() {
+		res, _, _ := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+		sent <- res
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	_, _, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"), pushy.WithTimeout(20*time.Millisecond))
+	Assert.True(errors.Is(err, context.DeadlineExceeded), "%v", err)
+	Assert.True(time.Since(start) < time.Second, "waiting for the send stops with the timeout")
+
+	release()
+	first := <-sent
+	Assert.Equal("PUSH1", first.ID)
+	// callers get their own copy of the response
+	first.Info.Devices = 0
+	again, _, _ := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.Equal(1, again.Info.Devices)
+	Assert.Len(server.Pushes(), 1)
+}
+
+type failingStore struct {
+	getErr error
+	setErr error
+}
+
+func (s failingStore) Get(key string) (*pushy.NotificationResponse, error) {
+	return nil, s.getErr
+}
+
+func (s failingStore) Set(key string, response *pushy.NotificationResponse) error {
+	return s.setErr
+}
+
+func TestDeduplicator_StoreErrors(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	request := pushy.SendNotificationRequest{To: []string{"D"}}
+
+	client := pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{Store: failingStore{getErr: errors.New("store is down")}})
+	_, _, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.EqualError(err, "store is down")
+	Assert.Equal(0, len(server.Pushes()), "nothing is sent when it isn't known if it was sent before")
+
+	var failedKey string
+	client = pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{
+		Store:        failingStore{setErr: errors.New("store is full")},
+		OnStoreError: func(key string, err error) { failedKey = key },
+	})
+	res, _, err := client.NotifyDeviceWithOptions(request, pushy.WithIdempotencyKey("A"))
+	Assert.Nil(err)
+	Assert.Equal("PUSH1", res.ID)
+	Assert.Equal("A", failedKey)
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	Assert := assert.New(t)
+	store := pushy.NewMemoryDedupStore(2, time.Hour)
+	for _, key := range []string{"A", "B"} {
+		Assert.Nil(store.Set(key, &pushy.NotificationResponse{Success: true, ID: key}))
+	}
+	// A is used last, so B is dropped for C
+	res, _ := store.Get("A")
+	Assert.Equal("A", res.ID)
+	store.Set("C", &pushy.NotificationResponse{ID: "C"})
+	Assert.Equal(2, store.Len())
+	res, _ = store.Get("B")
+	Assert.Nil(res)
+	res, _ = store.Get("C")
+	Assert.Equal("C", res.ID)
+
+	// stored responses are copies, info included
+	res.ID = "changed"
+	res, _ = store.Get("C")
+	Assert.Equal("C", res.ID)
+	stored := &pushy.NotificationResponse{ID: "D", Info: &pushy.NotificationInfo{Devices: 2, Failed: []string{"X"}}}
+	store.Set("D", stored)
+	stored.Info.Failed[0] = "changed by sender"
+	res, _ = store.Get("D")
+	Assert.Equal([]string{"X"}, res.Info.Failed)
+	res.Info.Devices = 0
+	res.Info.Failed[0] = "changed by caller"
+	res, _ = store.Get("D")
+	Assert.Equal(&pushy.NotificationInfo{Devices: 2, Failed: []string{"X"}}, res.Info)
+}
+
+func TestMemoryDedupStore_TTL(t *testing.T) {
+	Assert := assert.New(t)
+	store := pushy.NewMemoryDedupStore(0, 20*time.Millisecond)
+	store.Set("A", &pushy.NotificationResponse{ID: "A"})
+	res, _ := store.Get("A")
+	Assert.NotNil(res)
+	time.Sleep(30 * time.Millisecond)
+	res, _ = store.Get("A")
+	Assert.Nil(res)
+	Assert.Equal(0, store.Len())
+}
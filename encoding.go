@@ -0,0 +1,146 @@
+package pushy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// bufferPool holds buffers responses are read into, request bodies aren't pooled
+// because transport may still be reading them after the response has arrived
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// writerPool holds writers used for streaming requests, a writer is only used by the goroutine encoding request
+var writerPool = sync.Pool{
+	New: func() interface{} { return bufio.NewWriterSize(nil, 32*1024) },
+}
+
+// maxPooledBuffer keeps an occasional huge response from staying in the pool
+const maxPooledBuffer = 1 << 20
+
+// decodeResponse reads the whole body, so the connection can be reused, and decodes it into errRes for error
+// statuses or into posRes otherwise
+func (r requester) decodeResponse(response *http.Response, posRes interface{}, errRes interface{}) error {
+	defer response.Body.Close()
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	defer func() {
+		if buffer.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buffer)
+		}
+	}()
+	body, err := responseBody(response)
+	if err != nil {
+		return err
+	}
+	_, err = buffer.ReadFrom(body)
+	if response.StatusCode >= 400 {
+		r.codec.Unmarshal(buffer.Bytes(), errRes)
+		return StatusError{Code: response.StatusCode, Status: response.Status}
+	}
+	if err != nil {
+		return err
+	}
+	if buffer.Len() == 0 {
+		return io.EOF
+	}
+	return r.codec.Unmarshal(buffer.Bytes(), posRes)
+}
+
+// postStream sends request without holding all of its json in memory, recipients are written one by one into a pipe
+// while transport is sending what's already written. request is sent with chunked encoding
+func (r requester) postStream(ctx context.Context, url string, request SendNotificationRequest, posRes interface{}, errRes interface{}) error {
+	reader, writer := io.Pipe()
+	// closing reader stops the encoding goroutine if transport gave up before reading everything
+	defer reader.Close()
+	encoding := ""
+	if r.compressing(-1) {
+		encoding = "gzip"
+	}
+	go func() {
+		if encoding == "gzip" {
+			writer.CloseWithError(compressStream(writer, func(w io.Writer) error {
+				return r.encodeNotification(w, request)
+			}))
+			return
+		}
+		writer.CloseWithError(r.encodeNotification(writer, request))
+	}()
+	response, err := r.transmit(ctx, http.MethodPost, url, reader, encoding)
+	if err != nil {
+		return err
+	}
+	if encoding == "gzip" && response.StatusCode == http.StatusUnsupportedMediaType {
+		response.Body.Close()
+		reader.Close()
+		return r.withoutCompression().postStream(ctx, url, request, posRes, errRes)
+	}
+	return r.decodeResponse(response, posRes, errRes)
+}
+
+// encodeNotification writes request as json, "to" is written token by token instead of being encoded at once
+func (r requester) encodeNotification(w io.Writer, request SendNotificationRequest) error {
+	to := request.To
+	request.To = nil
+	// "to" is the first field, the rest of request is encoded as usual and appended after it
+	rest, err := r.codec.Marshal(request)
+	if err != nil {
+		return err
+	}
+	prefix := []byte(`{"to":null`)
+	if !bytes.HasPrefix(rest, prefix) {
+		// codec writes fields differently, there's no way to stream it
+		request.To = to
+		encoded, err := r.codec.Marshal(request)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(encoded)
+		return err
+	}
+	buffered := writerPool.Get().(*bufio.Writer)
+	buffered.Reset(w)
+	defer func() {
+		buffered.Reset(nil)
+		writerPool.Put(buffered)
+	}()
+	buffered.WriteString(`{"to":[`)
+	for i, token := range to {
+		if i > 0 {
+			buffered.WriteByte(',')
+		}
+		if err := writeJSONString(buffered, token); err != nil {
+			return err
+		}
+	}
+	buffered.WriteByte(']')
+	buffered.Write(rest[len(prefix):])
+	buffered.WriteByte('\n')
+	return buffered.Flush()
+}
+
+// writeJSONString writes value as a json string, tokens are plain ascii so they're written as they are
+func writeJSONString(w *bufio.Writer, value string) error {
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < 0x20 || c >= 0x7f || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(encoded)
+			return err
+		}
+	}
+	// errors of bufio.Writer stick, they're returned by Flush
+	w.WriteByte('"')
+	w.WriteString(value)
+	w.WriteByte('"')
+	return nil
+}
@@ -0,0 +1,140 @@
+package pushy_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+func campaign(recipients int) pushy.SendNotificationRequest {
+	to := make([]string, recipients)
+	for i := range to {
+		to[i] = fmt.Sprintf("%022x", i)
+	}
+	return pushy.SendNotificationRequest{
+		To:         to,
+		Data:       `{"message":"Hello World!"}`,
+		TimeToLive: 3600,
+	}
+}
+
+func TestNotifyDevice_Streaming(t *testing.T) {
+	Assert := assert.New(t)
+	var bodies []string
+	var chunked []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		chunked = append(chunked, r.ContentLength == -1)
+		w.Write([]byte(`{"success":true,"id":"PUSH"}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	sdk.SetStreamingThreshold(3)
+
+	request := campaign(3)
+	request.To[1] = `needs "escaping" <&>`
+	request.IOSNotification = pushy.IOSNotification{Body: "hi"}
+	request.AndroidOptions = pushy.AndroidOptions{Priority: pushy.AndroidPriorityHigh}
+	res, _, err := sdk.NotifyDevice(request)
+	Assert.Nil(err)
+	Assert.Equal("PUSH", res.ID)
+	_, _, err = sdk.NotifyDevice(campaign(2))
+	Assert.Nil(err)
+
+	expected, _ := json.Marshal(request)
+	Assert.JSONEq(string(expected), bodies[0])
+	expected, _ = json.Marshal(campaign(2))
+	Assert.JSONEq(string(expected), bodies[1])
+	Assert.Equal([]bool{true, false}, chunked, "only requests above threshold are streamed")
+}
+
+func TestNotifyDevice_StreamingError(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"too many recipients"}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	sdk.SetStreamingThreshold(1)
+	res, pushyErr, err := sdk.NotifyDevice(campaign(100000))
+	Assert.Nil(res)
+	Assert.Equal("too many recipients", pushyErr.Error)
+	Assert.Contains(err.Error(), "400")
+
+	sdk.SetAPIEndpoint("http://127.0.0.1:1")
+	_, _, err = sdk.NotifyDevice(campaign(10))
+	Assert.NotNil(err)
+}
+
+func TestDecodeResponse_EmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	_, _, err := sdk.Topics()
+	assert.Equal(t, io.EOF, err)
+}
+
+// BenchmarkNotifyDevice sends campaigns of different sizes to a local server which discards them,
+// "streamed" sends them with SetStreamingThreshold
+func BenchmarkNotifyDevice(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		w.Write([]byte(`{"success":true,"id":"5742fe0407c3674e226892f9"}`))
+	}))
+	defer server.Close()
+	for _, recipients := range []int{1, 1000, 10000} {
+		request := campaign(recipients)
+		for _, threshold := range []int{0, 1} {
+			name := fmt.Sprintf("to=%d", recipients)
+			if threshold > 0 {
+				name += "/streamed"
+			}
+			b.Run(name, func(b *testing.B) {
+				sdk := pushy.Create("SECRET", server.URL)
+				sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Second))
+				sdk.SetStreamingThreshold(threshold)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, _, err := sdk.NotifyDevice(request); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkDeviceInfo measures decoding of a response
+func BenchmarkDeviceInfo(b *testing.B) {
+	response, err := ioutil.ReadFile("testdata/device_info.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(response)
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Second))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := sdk.DeviceInfo("DEVICE"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
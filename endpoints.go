@@ -0,0 +1,347 @@
+package pushy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointPoolConfig configures an EndpointPool, zero values are replaced with defaults
+type EndpointPoolConfig struct {
+	// Cooldown is how long an endpoint is skipped once it's down, defaults to 30s.
+	// when every endpoint is down they're still tried, starting with the one which should recover first
+	Cooldown time.Duration
+	// FailureThreshold is how many consecutive failures put an endpoint down, defaults to 3
+	FailureThreshold int
+	// HedgeDelay enables hedged GET requests (DeviceInfo, NotificationStatus, Topics),
+	// if an endpoint doesn't respond within HedgeDelay the same request is sent to the next one as well
+	// and the first good response is used. 0 disables hedging
+	HedgeDelay time.Duration
+}
+
+// EndpointHealth is the health of a single endpoint of an EndpointPool
+type EndpointHealth struct {
+	Endpoint string
+	Healthy  bool
+	// Failures is the number of consecutive failed requests
+	Failures int
+}
+
+type endpointState struct {
+	failures  int
+	downUntil time.Time
+}
+
+// EndpointPool is an IHTTPClient which sends requests to an ordered list of endpoints,
+// like regional or self hosted pushy enterprise instances. requests are made to the first healthy endpoint
+// and moved to the next one on network errors or 5xx responses.
+// sending a notification (POST /push) is only moved when the endpoint couldn't even be connected to,
+// or when it has an Idempotency-Key (see WithIdempotencyKey), otherwise it could be delivered twice.
+// pushy has to use the first endpoint, pool rewrites urls for the other ones
+//  pool, err := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(10*time.Second),
+//  	[]string{"https://pushy.eu.example.com", "https://api.pushy.me"}, pushy.EndpointPoolConfig{})
+//  sdk := pushy.Create("token", pool.Primary())
+//  sdk.SetHTTPClient(pool)
+// it's safe to use from multiple goroutines
+type EndpointPool struct {
+	client    IHTTPClient
+	endpoints []string
+	config    EndpointPoolConfig
+
+	mu     sync.Mutex
+	health []endpointState
+}
+
+// NewEndpointPool creates a pool making requests with client, at least one endpoint is required
+func NewEndpointPool(client IHTTPClient, endpoints []string, config EndpointPoolConfig) (*EndpointPool, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("pushy: at least one endpoint is required")
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 3
+	}
+	trimmed := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		if err := validateURL("endpoint", endpoint); err != nil {
+			return nil, err
+		}
+		trimmed[i] = strings.TrimRight(endpoint, "/")
+	}
+	return &EndpointPool{
+		client:    client,
+		endpoints: trimmed,
+		config:    config,
+		health:    make([]endpointState, len(trimmed)),
+	}, nil
+}
+
+// Primary returns the first endpoint, it should be used as APIEndpoint of the client using the pool
+func (p *EndpointPool) Primary() string {
+	return p.endpoints[0]
+}
+
+// Health returns health of every endpoint in configured order
+func (p *EndpointPool) Health() []EndpointHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	health := make([]EndpointHealth, len(p.endpoints))
+	for i, endpoint := range p.endpoints {
+		health[i] = EndpointHealth{
+			Endpoint: endpoint,
+			Healthy:  !now.Before(p.health[i].downUntil),
+			Failures: p.health[i].failures,
+		}
+	}
+	return health
+}
+
+// Get sends a GET request, hedged if HedgeDelay is set
+func (p *EndpointPool) Get(url string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.Do(request)
+}
+
+// Post sends a POST request, body is buffered so it can be sent again to another endpoint
+func (p *EndpointPool) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	return p.Do(request)
+}
+
+// Do sends request to the endpoints, requests to urls which don't start with one of the endpoints are sent as they are
+func (p *EndpointPool) Do(request *http.Request) (*http.Response, error) {
+	path, ok := p.path(request.URL.String())
+	if !ok {
+		return p.client.Do(request)
+	}
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	attempt := func(ctx context.Context, endpoint int) (*http.Response, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		clone, err := http.NewRequest(request.Method, p.endpoints[endpoint]+path, reader)
+		if err != nil {
+			return nil, err
+		}
+		clone.Header = request.Header.Clone()
+		return p.client.Do(clone.WithContext(ctx))
+	}
+	order := p.order()
+	if p.config.HedgeDelay > 0 && request.Method == http.MethodGet && len(order) > 1 {
+		return p.hedged(request.Context(), order, attempt)
+	}
+	return p.sequential(request.Context(), order, resendable(request.Method, path, request.Header), attempt)
+}
+
+// path returns the part of url after the endpoint it starts with
+func (p *EndpointPool) path(url string) (string, bool) {
+	for _, endpoint := range p.endpoints {
+		if strings.HasPrefix(url, endpoint) {
+			rest := url[len(endpoint):]
+			if rest == "" || rest[0] == '/' || rest[0] == '?' {
+				return rest, true
+			}
+		}
+	}
+	return "", false
+}
+
+// order returns indexes of healthy endpoints in configured order followed by the ones which are down
+func (p *EndpointPool) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var healthy, down []int
+	for i := range p.endpoints {
+		if now.Before(p.health[i].downUntil) {
+			down = append(down, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	// the one which should recover first goes first
+	for i := 1; i < len(down); i++ {
+		for j := i; j > 0 && p.health[down[j]].downUntil.Before(p.health[down[j-1]].downUntil); j-- {
+			down[j], down[j-1] = down[j-1], down[j]
+		}
+	}
+	return append(healthy, down...)
+}
+
+func (p *EndpointPool) record(endpoint int, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !failed {
+		p.health[endpoint] = endpointState{}
+		return
+	}
+	p.health[endpoint].failures++
+	if p.health[endpoint].failures >= p.config.FailureThreshold {
+		p.health[endpoint].downUntil = time.Now().Add(p.config.Cooldown)
+	}
+}
+
+func isEndpointFailure(response *http.Response, err error) bool {
+	return err != nil || response.StatusCode >= 500
+}
+
+// resendable reports whether a request which failed on an endpoint can be sent to the next one,
+// a notification which may have been received would be sent twice unless it has an Idempotency-Key
+func resendable(method string, path string, header http.Header) bool {
+	if method != http.MethodPost || header.Get(headerIdempotencyKey) != "" {
+		return true
+	}
+	return strings.SplitN(path, "?", 2)[0] != "/push"
+}
+
+// notSent reports whether err proves request never reached the endpoint, because connection couldn't be made
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// sequential tries endpoints in order until one doesn't fail, requests which aren't resendable
+// only move on when they weren't sent
+func (p *EndpointPool) sequential(ctx context.Context, order []int, canResend bool, attempt func(context.Context, int) (*http.Response, error)) (*http.Response, error) {
+	var response *http.Response
+	var err error
+	for i, endpoint := range order {
+		response, err = attempt(ctx, endpoint)
+		failed := isEndpointFailure(response, err)
+		if ctx.Err() != nil {
+			// caller gave up, it doesn't say anything about the endpoint
+			return response, err
+		}
+		p.record(endpoint, failed)
+		if !failed || i == len(order)-1 || !canResend && !notSent(err) {
+			break
+		}
+		if response != nil {
+			response.Body.Close()
+		}
+	}
+	return response, err
+}
+
+type hedgeResult struct {
+	endpoint int
+	response *http.Response
+	err      error
+	cancel   context.CancelFunc
+}
+
+// hedged starts request on the first endpoint and on the next one every time HedgeDelay passes
+// or an attempt fails, first good response wins and the others are cancelled
+func (p *EndpointPool) hedged(ctx context.Context, order []int, attempt func(context.Context, int) (*http.Response, error)) (*http.Response, error) {
+	results := make(chan hedgeResult, len(order))
+	cancels := make(map[int]context.CancelFunc, len(order))
+	start := func(endpoint int) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels[endpoint] = cancel
+		go func() {
+			response, err := attempt(attemptCtx, endpoint)
+			results <- hedgeResult{endpoint: endpoint, response: response, err: err, cancel: cancel}
+		}()
+	}
+	next := 1
+	start(order[0])
+	timer := time.NewTimer(p.config.HedgeDelay)
+	defer timer.Stop()
+
+	var last hedgeResult
+	for pending := 1; pending > 0; {
+		select {
+		case <-timer.C:
+			if next < len(order) {
+				start(order[next])
+				next++
+				pending++
+				timer.Reset(p.config.HedgeDelay)
+			}
+		case result := <-results:
+			pending--
+			failed := isEndpointFailure(result.response, result.err)
+			if ctx.Err() == nil {
+				p.record(result.endpoint, failed)
+			}
+			if !failed {
+				// cancel the losers and clean up after them, winner's context lives until its body is closed
+				for endpoint, cancel := range cancels {
+					if endpoint != result.endpoint {
+						cancel()
+					}
+				}
+				go drain(results, pending)
+				result.response.Body = cancelOnClose{ReadCloser: result.response.Body, cancel: result.cancel}
+				return result.response, nil
+			}
+			if last.response != nil {
+				last.response.Body.Close()
+			}
+			if last.cancel != nil {
+				last.cancel()
+			}
+			last = result
+			if next < len(order) && ctx.Err() == nil {
+				start(order[next])
+				next++
+				pending++
+				timer.Reset(p.config.HedgeDelay)
+			}
+		}
+	}
+	if last.response != nil {
+		last.response.Body = cancelOnClose{ReadCloser: last.response.Body, cancel: last.cancel}
+	} else {
+		last.cancel()
+	}
+	return last.response, last.err
+}
+
+// drain closes responses of cancelled attempts which still arrive
+func drain(results <-chan hedgeResult, pending int) {
+	for ; pending > 0; pending-- {
+		result := <-results
+		if result.response != nil {
+			result.response.Body.Close()
+		}
+	}
+}
+
+// cancelOnClose releases context of a request once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
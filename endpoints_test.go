@@ -0,0 +1,178 @@
+package pushy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// requestsOf returns requests server received as method, path and body
+func requestsOf(server *pushytest.Server) []string {
+	var requests []string
+	for _, request := range server.Requests() {
+		requests = append(requests, request.String())
+	}
+	return requests
+}
+
+func TestNewEndpointPool(t *testing.T) {
+	Assert := assert.New(t)
+	_, err := pushy.NewEndpointPool(nil, nil, pushy.EndpointPoolConfig{})
+	Assert.NotNil(err)
+	_, err = pushy.NewEndpointPool(nil, []string{"api.pushy.me"}, pushy.EndpointPoolConfig{})
+	Assert.NotNil(err)
+	pool, err := pushy.NewEndpointPool(nil, []string{"https://eu.example.com/", "https://api.pushy.me"}, pushy.EndpointPoolConfig{})
+	Assert.Nil(err)
+	Assert.Equal("https://eu.example.com", pool.Primary())
+}
+
+func TestEndpointPool_Failover(t *testing.T) {
+	Assert := assert.New(t)
+	primary, secondary := pushytest.NewServer(), pushytest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+	pool, _ := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(time.Second),
+		[]string{primary.URL, secondary.URL}, pushy.EndpointPoolConfig{Cooldown: time.Hour, FailureThreshold: 2})
+	client := pushy.Create("SECRET", pool.Primary())
+	client.SetHTTPClient(pool)
+
+	_, _, err := client.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Len(primary.Pushes(), 1)
+
+	// primary may have received the notification, so it isn't sent again without an idempotency key
+	primary.SetStatus(503)
+	_, _, err = client.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Contains(err.Error(), "503")
+	Assert.Empty(secondary.Requests())
+	Assert.Equal([]pushy.EndpointHealth{
+		{Endpoint: primary.URL, Healthy: true, Failures: 1},
+		{Endpoint: secondary.URL, Healthy: true},
+	}, pool.Health(), "endpoint is up until FailureThreshold is reached")
+
+	_, _, err = client.NotifyDeviceWithOptions(pushy.SendNotificationRequest{To: []string{"D"}}, pushy.WithIdempotencyKey("EVENT"))
+	Assert.Nil(err)
+	Assert.Len(secondary.Pushes(), 1)
+	Assert.Equal(requestsOf(primary)[2], requestsOf(secondary)[0], "body is sent again")
+	Assert.Equal("EVENT", secondary.Requests()[0].Header.Get("Idempotency-Key"))
+	Assert.Equal([]pushy.EndpointHealth{
+		{Endpoint: primary.URL, Healthy: false, Failures: 2},
+		{Endpoint: secondary.URL, Healthy: true},
+	}, pool.Health())
+
+	// primary is skipped while it's down
+	_, _, err = client.DeleteNotification("PUSH_ID")
+	Assert.Nil(err)
+	Assert.Len(primary.Requests(), 3)
+	Assert.Equal("DELETE /pushes/PUSH_ID", requestsOf(secondary)[1])
+
+	// when everything is down the last failure is returned
+	secondary.SetStatus(500)
+	_, pushyErr, err := client.Topics()
+	Assert.Contains(err.Error(), "503")
+	Assert.Equal("unavailable", pushyErr.Error)
+	Assert.Len(primary.Requests(), 4, "down endpoints are still tried as last resort")
+}
+
+func TestEndpointPool_Failover_Reads(t *testing.T) {
+	Assert := assert.New(t)
+	primary, secondary := pushytest.NewServer(), pushytest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+	primary.SetStatus(502)
+	pool, _ := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(time.Second),
+		[]string{primary.URL, secondary.URL}, pushy.EndpointPoolConfig{})
+	client := pushy.Create("SECRET", pool.Primary())
+	client.SetHTTPClient(pool)
+
+	// everything but sending a notification can be sent again
+	_, _, err := client.DevicePresence("DEVICE")
+	Assert.Nil(err)
+	_, _, err = client.SubscribeToTopic("DEVICE", "news")
+	Assert.Nil(err)
+	Assert.Equal([]string{`POST /devices/presence {"tokens":["DEVICE"]}`, `POST /devices/subscribe {"token":"DEVICE","topics":["news"]}`}, requestsOf(secondary))
+}
+
+func TestEndpointPool_NetworkError(t *testing.T) {
+	Assert := assert.New(t)
+	secondary := pushytest.NewServer()
+	defer secondary.Close()
+	pool, _ := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(time.Second),
+		[]string{"http://127.0.0.1:1", secondary.URL}, pushy.EndpointPoolConfig{})
+	client := pushy.Create("SECRET", pool.Primary())
+	client.SetHTTPClient(pool)
+	status, _, err := client.NotificationStatus("PUSH_ID")
+	Assert.Nil(err)
+	Assert.Equal([]string{"DEVICE"}, status.Push.PendingDevices)
+	Assert.Len(secondary.Requests(), 1)
+
+	// connection wasn't even made, so the notification goes to the next endpoint
+	_, _, err = client.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Len(secondary.Pushes(), 1)
+	Assert.Equal(pushy.EndpointHealth{Endpoint: "http://127.0.0.1:1", Healthy: true, Failures: 2}, pool.Health()[0])
+	client.Topics()
+	Assert.False(pool.Health()[0].Healthy, "3 failures put an endpoint down by default")
+}
+
+func TestEndpointPool_Hedged(t *testing.T) {
+	Assert := assert.New(t)
+	primary, secondary := pushytest.NewServer(), pushytest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+	pool, _ := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(5*time.Second),
+		[]string{primary.URL, secondary.URL}, pushy.EndpointPoolConfig{HedgeDelay: 20 * time.Millisecond})
+	client := pushy.Create("SECRET", pool.Primary())
+	client.SetHTTPClient(pool)
+
+	_, _, err := client.NotificationStatus("PUSH_ID")
+	Assert.Nil(err)
+	Assert.Len(primary.Requests(), 1)
+	Assert.Empty(secondary.Requests(), "fast primary isn't hedged")
+
+	primary.SetDelay(2 * time.Second)
+	started := time.Now()
+	status, _, err := client.NotificationStatus("PUSH_ID")
+	Assert.Nil(err)
+	Assert.Equal([]string{"DEVICE"}, status.Push.PendingDevices)
+	Assert.Len(secondary.Requests(), 1)
+	Assert.True(time.Since(started) < time.Second, "slow primary is cancelled, secondary answered")
+	Assert.True(pool.Health()[0].Healthy, "cancelled attempt doesn't count as failure")
+
+	// writes are never hedged
+	primary.SetDelay(100 * time.Millisecond)
+	_, _, err = client.NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Len(secondary.Requests(), 1)
+}
+
+func TestEndpointPool_HedgedFailure(t *testing.T) {
+	Assert := assert.New(t)
+	primary, secondary := pushytest.NewServer(), pushytest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+	primary.SetStatus(500)
+	secondary.SetStatus(502)
+	pool, _ := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(time.Second),
+		[]string{primary.URL, secondary.URL}, pushy.EndpointPoolConfig{HedgeDelay: time.Second})
+	client := pushy.Create("SECRET", pool.Primary())
+	client.SetHTTPClient(pool)
+	started := time.Now()
+	_, pushyErr, err := client.DeviceInfo("D")
+	Assert.Contains(err.Error(), "502")
+	Assert.Equal("unavailable", pushyErr.Error)
+	Assert.True(time.Since(started) < time.Second, "failure starts next attempt right away")
+}
+
+func TestEndpointPool_OtherURLs(t *testing.T) {
+	other := pushytest.NewServer()
+	defer other.Close()
+	pool, _ := pushy.NewEndpointPool(pushy.GetDefaultHTTPClient(time.Second), []string{"https://api.pushy.me"}, pushy.EndpointPoolConfig{})
+	response, err := pool.Get(other.URL + "/topics")
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, []string{"GET /topics"}, requestsOf(other))
+}
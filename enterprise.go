@@ -0,0 +1,122 @@
+package pushy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// EnterpriseConfig configures a client for a self hosted pushy enterprise instance,
+// which usually sits behind a private CA, sometimes requires client certificates or is only reachable through a proxy
+type EnterpriseConfig struct {
+	APIToken string
+	// APIEndpoint is the url of the instance, like https://pushy.internal.example.com
+	APIEndpoint string
+	// Timeout defaults to DefaultTimeout
+	Timeout time.Duration
+	// CAFile and CAPEM add certificates of private CAs to the system roots, both can be used at once
+	CAFile string
+	CAPEM  []byte
+	// CertFile and KeyFile are the client certificate and its key in PEM, for instances requiring mTLS
+	CertFile string
+	KeyFile  string
+	// ProxyURL is used for every request, when it's empty HTTPS_PROXY/HTTP_PROXY/NO_PROXY from environment are used
+	ProxyURL string
+	// ServerName overrides the name certificate of the instance is checked against,
+	// useful when it's reached by an ip or a name which isn't in the certificate
+	ServerName string
+	// Transport tunes connection pooling and timeouts, its TLSConfig and Proxy are replaced by the ones above
+	Transport TransportConfig
+}
+
+// TLSConfig builds tls configuration with CAs and client certificate of config
+func (c EnterpriseConfig) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+	if c.CAFile != "" || len(c.CAPEM) > 0 {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		if c.CAFile != "" {
+			content, err := ioutil.ReadFile(c.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			if !roots.AppendCertsFromPEM(content) {
+				return nil, fmt.Errorf("pushy: no certificates found in %s", c.CAFile)
+			}
+		}
+		if len(c.CAPEM) > 0 && !roots.AppendCertsFromPEM(c.CAPEM) {
+			return nil, errors.New("pushy: no certificates found in CAPEM")
+		}
+		config.RootCAs = roots
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("pushy: both CertFile and KeyFile are required for client certificates")
+		}
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// NewEnterpriseHTTPClient creates a http client using TLS and proxy settings of config
+func NewEnterpriseHTTPClient(config EnterpriseConfig) (IHTTPClient, error) {
+	tlsConfig, err := config.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("pushy: proxy url: %v", err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("pushy: proxy url must be absolute, got %q", config.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	transport := config.Transport
+	transport.TLSConfig = tlsConfig
+	transport.Proxy = proxy
+	return NewHTTPClient(timeout, transport), nil
+}
+
+// CreateEnterprise creates a client for a pushy enterprise instance with http client from NewEnterpriseHTTPClient
+//  sdk, err := pushy.CreateEnterprise(pushy.EnterpriseConfig{
+//  	APIToken:    "token",
+//  	APIEndpoint: "https://pushy.internal.example.com",
+//  	CAFile:      "/etc/ssl/internal-ca.pem",
+//  })
+func CreateEnterprise(config EnterpriseConfig) (*Pushy, error) {
+	if config.APIEndpoint == "" {
+		return nil, errors.New("pushy: api endpoint is required for enterprise instances")
+	}
+	if err := validateURL("api_endpoint", config.APIEndpoint); err != nil {
+		return nil, err
+	}
+	client, err := NewEnterpriseHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	sdk := Create(config.APIToken, config.APIEndpoint)
+	sdk.SetHTTPClient(client)
+	return sdk, nil
+}
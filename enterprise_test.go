@@ -0,0 +1,166 @@
+package pushy_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+func enterpriseHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"topics":[{"name":"news","subscribers":1}]}`))
+}
+
+func serverCAPEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// clientCertificate creates a self signed client certificate, writes it with its key to dir and returns it
+func clientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dispatcher"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certificate, certFile, keyFile
+}
+
+func TestCreateEnterprise_PrivateCA(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(enterpriseHandler))
+	defer server.Close()
+
+	// without the CA certificate of the instance can't be verified
+	sdk, err := pushy.CreateEnterprise(pushy.EnterpriseConfig{APIToken: "SECRET", APIEndpoint: server.URL})
+	Assert.Nil(err)
+	_, _, err = sdk.Topics()
+	Assert.NotNil(err)
+
+	sdk, err = pushy.CreateEnterprise(pushy.EnterpriseConfig{
+		APIToken:    "SECRET",
+		APIEndpoint: server.URL,
+		CAPEM:       serverCAPEM(server),
+		Timeout:     time.Second,
+	})
+	Assert.Nil(err)
+	topics, _, err := sdk.Topics()
+	Assert.Nil(err)
+	Assert.Equal("news", topics.Topics[0].Name)
+	Assert.Equal(time.Second, sdk.GetHTTPClient().(*http.Client).Timeout)
+
+	dir, _ := ioutil.TempDir("", "enterprise")
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(caFile, serverCAPEM(server), 0600)
+	sdk, err = pushy.CreateEnterprise(pushy.EnterpriseConfig{APIEndpoint: server.URL, CAFile: caFile})
+	Assert.Nil(err)
+	_, _, err = sdk.Topics()
+	Assert.Nil(err)
+	Assert.Equal(pushy.DefaultTimeout, sdk.GetHTTPClient().(*http.Client).Timeout)
+}
+
+func TestCreateEnterprise_MutualTLS(t *testing.T) {
+	Assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "enterprise")
+	defer os.RemoveAll(dir)
+	clientCert, certFile, keyFile := clientCertificate(t, dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(enterpriseHandler))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	sdk, _ := pushy.CreateEnterprise(pushy.EnterpriseConfig{APIEndpoint: server.URL, CAPEM: serverCAPEM(server)})
+	_, _, err := sdk.Topics()
+	Assert.NotNil(err, "client certificate is required")
+
+	sdk, err = pushy.CreateEnterprise(pushy.EnterpriseConfig{
+		APIEndpoint: server.URL,
+		CAPEM:       serverCAPEM(server),
+		CertFile:    certFile,
+		KeyFile:     keyFile,
+	})
+	Assert.Nil(err)
+	_, _, err = sdk.Topics()
+	Assert.Nil(err)
+}
+
+func TestCreateEnterprise_Proxy(t *testing.T) {
+	Assert := assert.New(t)
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		enterpriseHandler(w, r)
+	}))
+	defer proxy.Close()
+	sdk, err := pushy.CreateEnterprise(pushy.EnterpriseConfig{
+		APIToken:    "SECRET",
+		APIEndpoint: "http://pushy.internal.example.com",
+		ProxyURL:    proxy.URL,
+	})
+	Assert.Nil(err)
+	_, _, err = sdk.Topics()
+	Assert.Nil(err)
+	Assert.Equal([]string{"http://pushy.internal.example.com/topics?api_key=SECRET"}, proxied)
+}
+
+func TestCreateEnterprise_InvalidConfig(t *testing.T) {
+	Assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "enterprise")
+	defer os.RemoveAll(dir)
+	notPEM := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600)
+
+	configs := []pushy.EnterpriseConfig{
+		{},
+		{APIEndpoint: "pushy.internal.example.com"},
+		{APIEndpoint: "https://pushy.internal.example.com", CAFile: filepath.Join(dir, "missing.pem")},
+		{APIEndpoint: "https://pushy.internal.example.com", CAFile: notPEM},
+		{APIEndpoint: "https://pushy.internal.example.com", CAPEM: []byte("not a certificate")},
+		{APIEndpoint: "https://pushy.internal.example.com", CertFile: notPEM},
+		{APIEndpoint: "https://pushy.internal.example.com", CertFile: notPEM, KeyFile: notPEM},
+		{APIEndpoint: "https://pushy.internal.example.com", ProxyURL: "proxy:3128"},
+	}
+	for _, config := range configs {
+		_, err := pushy.CreateEnterprise(config)
+		Assert.NotNil(err, "%+v", config)
+	}
+}
@@ -0,0 +1,169 @@
+package pushy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// requester makes requests with settings read once when an operation starts
+type requester struct {
+	client               IHTTPClient
+	codec                Codec
+	compressionThreshold int
+	gzipRejected         *int32
+	streamingThreshold   int
+	// call is the operation requests are made for, nil when requests aren't made for an operation
+	call *call
+}
+
+// call is the state of a single operation, shared by all the requests it makes
+type call struct {
+	options CallOptions
+	// headers are the names response metadata is read from
+	headers MetaHeaders
+	// response and err are the outcome of the last request
+	response *http.Response
+	err      error
+}
+
+// do runs a single operation, every call to pushy goes through it. req is encoded as body unless it's nil,
+// response is decoded into Resp or into Error when pushy responds with an error status
+func do[Req any, Resp any](ctx context.Context, p *Pushy, method string, path string, req *Req, opts ...CallOption) (*Resp, *Error, error) {
+	c := &call{options: NewCallOptions(opts...), headers: p.getMetaHeaders()}
+	if c.options.Meta != nil {
+		*c.options.Meta = ResponseMeta{}
+		defer func(start time.Time) {
+			c.options.Meta.Latency = time.Since(start)
+		}(time.Now())
+	}
+	if c.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+	}
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	var response *Resp
+	var pushyErr *Error
+	var err error
+	for attempt := 1; ; attempt++ {
+		c.response, c.err = nil, nil
+		err = p.withCredentials(path, func(r requester, url string) error {
+			response, pushyErr = nil, nil
+			r.call = c
+			return r.send(ctx, method, url, body, &response, &pushyErr)
+		})
+		wait, retry := c.backoff(ctx, attempt)
+		if !retry || sleep(ctx, wait) != nil {
+			break
+		}
+	}
+	if err != nil {
+		response = nil
+	}
+	return response, pushyErr, err
+}
+
+// sleep waits for d unless ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// send encodes body, sends it and decodes the response into posRes or errRes
+func (r requester) send(ctx context.Context, method string, url string, body interface{}, posRes interface{}, errRes interface{}) error {
+	if request, ok := body.(*SendNotificationRequest); ok && r.streamingThreshold > 0 && len(request.To) >= r.streamingThreshold {
+		return r.postStream(ctx, url, *request, posRes, errRes)
+	}
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = r.codec.Marshal(body); err != nil {
+			return err
+		}
+	}
+	response, err := r.roundTrip(ctx, method, url, encoded)
+	if err != nil {
+		return err
+	}
+	return r.decodeResponse(response, posRes, errRes)
+}
+
+// roundTrip sends encoded body, compressed if it's big enough and endpoint accepts it
+func (r requester) roundTrip(ctx context.Context, method string, url string, encoded []byte) (*http.Response, error) {
+	if encoded != nil && r.compressing(len(encoded)) {
+		compressed, err := compress(encoded)
+		if err != nil {
+			return nil, err
+		}
+		response, err := r.transmit(ctx, method, url, compressed, "gzip")
+		if err != nil || response.StatusCode != http.StatusUnsupportedMediaType {
+			return response, err
+		}
+		response.Body.Close()
+		r = r.withoutCompression()
+	}
+	var body io.Reader
+	if encoded != nil {
+		body = bytes.NewReader(encoded)
+	}
+	return r.transmit(ctx, method, url, body, "")
+}
+
+// transmit sends a request with client, Get and Post of client are used when they're enough
+// so IHTTPClient implementations keep seeing the same calls they always did,
+// Do is used when request needs a context or additional headers
+func (r requester) transmit(ctx context.Context, method string, url string, body io.Reader, encoding string) (*http.Response, error) {
+	var options CallOptions
+	if r.call != nil {
+		options = r.call.options
+	}
+	if encoding == "" && ctx.Done() == nil && len(options.Header) == 0 && options.IdempotencyKey == "" {
+		switch method {
+		case http.MethodGet:
+			return r.observe(r.client.Get(url))
+		case http.MethodPost:
+			return r.observe(r.client.Post(url, "application/json", body))
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range options.Header {
+		request.Header[key] = append([]string(nil), values...)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if encoding != "" {
+		request.Header.Set("Content-Encoding", encoding)
+	}
+	if options.IdempotencyKey != "" {
+		request.Header.Set(headerIdempotencyKey, options.IdempotencyKey)
+	}
+	return r.observe(r.client.Do(request))
+}
+
+// observe records outcome of a request in call
+func (r requester) observe(response *http.Response, err error) (*http.Response, error) {
+	if r.call == nil {
+		return response, err
+	}
+	r.call.response, r.call.err = response, err
+	if r.call.options.Meta != nil {
+		r.call.options.Meta.observe(response, r.call.headers)
+	}
+	return response, err
+}
@@ -0,0 +1,205 @@
+package pushy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingClient records which of IHTTPClient methods were called
+type recordingClient struct {
+	client *http.Client
+	calls  []string
+}
+
+func (c *recordingClient) Get(url string) (*http.Response, error) {
+	c.calls = append(c.calls, "Get")
+	return c.client.Get(url)
+}
+
+func (c *recordingClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	c.calls = append(c.calls, "Post")
+	return c.client.Post(url, contentType, body)
+}
+
+func (c *recordingClient) Do(request *http.Request) (*http.Response, error) {
+	c.calls = append(c.calls, "Do")
+	return c.client.Do(request)
+}
+
+type networkFailure struct{}
+
+func (networkFailure) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+type unmarshalable struct {
+	Fn func()
+}
+
+func respondWith(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func TestDo_ErrorMatrix(t *testing.T) {
+	cases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		offline  bool
+		request  interface{}
+		response *SimpleSuccess
+		pushyErr *Error
+		err      string
+		// status is the code of StatusError, 0 when err isn't one
+		status int
+	}{
+		{name: "success", handler: respondWith(200, `{"success":true}`), response: &SimpleSuccess{Success: true}},
+		{name: "network error", offline: true, err: "connection refused"},
+		{name: "4xx with error", handler: respondWith(400, `{"error":"bad token"}`), pushyErr: &Error{Error: "bad token"}, err: "400 400 Bad Request", status: 400},
+		{name: "4xx with invalid body", handler: respondWith(404, `<html>`), err: "404 404 Not Found", status: 404},
+		{name: "4xx with empty body", handler: respondWith(403, ``), err: "403 403 Forbidden", status: 403},
+		{name: "401", handler: respondWith(401, `{"error":"invalid api key"}`), pushyErr: &Error{Error: "invalid api key"}, err: "401 401 Unauthorized", status: 401},
+		{name: "429", handler: respondWith(429, `{"error":"rate limited"}`), pushyErr: &Error{Error: "rate limited"}, err: "429 429 Too Many Requests", status: 429},
+		{name: "5xx", handler: respondWith(502, `{"error":"upstream"}`), pushyErr: &Error{Error: "upstream"}, err: "502 502 Bad Gateway", status: 502},
+		{name: "5xx with empty body", handler: respondWith(503, ``), err: "503 503 Service Unavailable", status: 503},
+		{name: "2xx with invalid json", handler: respondWith(200, `{"success":tru`), err: "unexpected end of JSON input"},
+		{name: "2xx with empty body", handler: respondWith(200, ``), err: io.EOF.Error()},
+		{name: "marshal error", handler: respondWith(200, `{"success":true}`), request: &unmarshalable{}, err: "json: unsupported type: func()"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			Assert := assert.New(t)
+			client := &http.Client{Timeout: time.Second}
+			url := "http://pushy.invalid"
+			if c.offline {
+				client.Transport = networkFailure{}
+			} else {
+				server := httptest.NewServer(c.handler)
+				defer server.Close()
+				url = server.URL
+			}
+			sdk := Create("SECRET", url)
+			sdk.SetHTTPClient(client)
+			var response *SimpleSuccess
+			var pushyErr *Error
+			var err error
+			if request, ok := c.request.(*unmarshalable); ok {
+				response, pushyErr, err = do[unmarshalable, SimpleSuccess](context.Background(), sdk, http.MethodPost, "/", request)
+			} else {
+				response, pushyErr, err = do[struct{}, SimpleSuccess](context.Background(), sdk, http.MethodGet, "/", nil)
+			}
+			Assert.Equal(c.response, response)
+			Assert.Equal(c.pushyErr, pushyErr)
+			if c.err == "" {
+				Assert.Nil(err)
+				return
+			}
+			if Assert.NotNil(err) {
+				Assert.Contains(err.Error(), c.err)
+			}
+			var statusErr StatusError
+			if c.status == 0 {
+				Assert.False(errors.As(err, &statusErr))
+				return
+			}
+			if Assert.True(errors.As(err, &statusErr)) {
+				Assert.Equal(c.status, statusErr.Code)
+				Assert.Equal(http.StatusText(c.status), statusErr.Status[4:])
+			}
+		})
+	}
+}
+
+func TestDo_Context(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(respondWith(200, `{"success":true}`))
+	defer server.Close()
+	client := &recordingClient{client: &http.Client{Timeout: time.Second}}
+	sdk := Create("SECRET", server.URL)
+	sdk.SetHTTPClient(client)
+
+	// background context keeps using Get and Post so existing clients see no difference
+	_, _, err := do[struct{}, SimpleSuccess](context.Background(), sdk, http.MethodGet, "/", nil)
+	Assert.Nil(err)
+	_, _, err = do[DevicePresenceRequest, SimpleSuccess](context.Background(), sdk, http.MethodPost, "/", &DevicePresenceRequest{})
+	Assert.Nil(err)
+	_, _, err = do[struct{}, SimpleSuccess](context.Background(), sdk, http.MethodDelete, "/", nil)
+	Assert.Nil(err)
+	Assert.Equal([]string{"Get", "Post", "Do"}, client.calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	response, _, err := do[struct{}, SimpleSuccess](ctx, sdk, http.MethodGet, "/", nil)
+	Assert.Nil(response)
+	if Assert.NotNil(err) {
+		Assert.Contains(err.Error(), context.Canceled.Error())
+	}
+	Assert.Equal("Do", client.calls[3])
+}
+
+func TestDo_RetriesUnauthorized(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "NEW" {
+			respondWith(401, `{"error":"expired"}`)(w, r)
+			return
+		}
+		respondWith(200, `{"success":true}`)(w, r)
+	}))
+	defer server.Close()
+	credentials := &pushytest.Credentials{Tokens: []string{"OLD", "NEW"}}
+	sdk := Create("", server.URL)
+	sdk.SetHTTPClient(&http.Client{Timeout: time.Second})
+	sdk.SetCredentialProvider(credentials)
+
+	response, pushyErr, err := do[struct{}, SimpleSuccess](context.Background(), sdk, http.MethodGet, "/", nil)
+	Assert.Nil(err)
+	Assert.Nil(pushyErr, "error of the rejected attempt isn't returned")
+	Assert.Equal(&SimpleSuccess{Success: true}, response)
+	Assert.Equal(1, credentials.Refreshed())
+}
+
+func TestDo_Compression(t *testing.T) {
+	Assert := assert.New(t)
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			respondWith(415, `{"error":"unsupported"}`)(w, r)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if !bytes.Equal(body, []byte(`{"tokens":["D"]}`)) {
+			respondWith(400, `{"error":"bad body"}`)(w, r)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(`{"success":true}`))
+		writer.Close()
+	}))
+	defer server.Close()
+	sdk := Create("SECRET", server.URL)
+	sdk.SetHTTPClient(&http.Client{Transport: &http.Transport{DisableCompression: true}, Timeout: time.Second})
+	sdk.SetCompressionThreshold(1)
+
+	response, pushyErr, err := do[DevicePresenceRequest, SimpleSuccess](context.Background(), sdk, http.MethodPost, "/", &DevicePresenceRequest{Tokens: []string{"D"}})
+	Assert.Nil(err)
+	Assert.Nil(pushyErr)
+	Assert.Equal(&SimpleSuccess{Success: true}, response)
+	Assert.Equal([]string{"gzip", ""}, encodings)
+}
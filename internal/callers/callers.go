@@ -0,0 +1,141 @@
+// Package callers authenticates services using pushy-relay and pushy-grpc,
+// every caller has its own api key and list of operations it's allowed to use
+package callers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// operations callers can be allowed to use, "*" allows all of them
+const (
+	OpSend             = "send"
+	OpDeviceInfo       = "device.info"
+	OpDevicePresence   = "device.presence"
+	OpPushStatus       = "push.status"
+	OpPushDelete       = "push.delete"
+	OpTopicSubscribe   = "topic.subscribe"
+	OpTopicUnsubscribe = "topic.unsubscribe"
+	OpTopicList        = "topic.list"
+)
+
+// Caller is a service allowed to use pushy through a relay, it authenticates with Authorization: Bearer <key>
+type Caller struct {
+	Name       string   `json:"name"`
+	Key        string   `json:"key"`
+	Operations []string `json:"operations"`
+	// RequestsPerSecond limits how often the caller may call, 0 doesn't limit it
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst is how many calls can be made at once after the caller was idle, defaults to RequestsPerSecond rounded up
+	Burst int `json:"burst"`
+}
+
+// Allows reports whether caller may use operation
+func (c Caller) Allows(operation string) bool {
+	for _, allowed := range c.Operations {
+		if allowed == operation || allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the caller with key, keys are compared in constant time
+func Find(callers []Caller, key string) (Caller, bool) {
+	if key == "" {
+		return Caller{}, false
+	}
+	for _, c := range callers {
+		if subtle.ConstantTimeCompare([]byte(c.Key), []byte(key)) == 1 {
+			return c, true
+		}
+	}
+	return Caller{}, false
+}
+
+// Validate checks every caller has a name and a key and names aren't used twice
+func Validate(callers []Caller) error {
+	if len(callers) == 0 {
+		return errors.New("at least one caller is required")
+	}
+	names := map[string]bool{}
+	for _, caller := range callers {
+		if caller.Name == "" || caller.Key == "" {
+			return errors.New("callers need a name and a key")
+		}
+		if caller.RequestsPerSecond < 0 || caller.Burst < 0 {
+			return fmt.Errorf("caller %q: requests_per_second and burst can't be negative", caller.Name)
+		}
+		if names[caller.Name] {
+			return fmt.Errorf("caller %q is defined twice", caller.Name)
+		}
+		names[caller.Name] = true
+	}
+	return nil
+}
+
+// Limiter enforces RequestsPerSecond of callers, every caller has a bucket of Burst calls
+// which is refilled at RequestsPerSecond. it's safe to use from multiple goroutines
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a Limiter with full buckets
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// BurstSize is the most calls c can make at once, Burst or RequestsPerSecond rounded up.
+// it's 0 for callers without a limit
+func (c Caller) BurstSize() int {
+	if c.Burst > 0 {
+		return c.Burst
+	}
+	return int(math.Ceil(c.RequestsPerSecond))
+}
+
+// Allow takes a call from bucket of c, when it's empty it returns false and how long until the next call is allowed
+func (l *Limiter) Allow(c Caller) (bool, time.Duration) {
+	return l.AllowN(c, 1)
+}
+
+// AllowN takes n calls from bucket of c at once, like a batch which makes n requests. when there aren't enough
+// of them it returns false and how long until there are, n over BurstSize is never allowed
+func (l *Limiter) AllowN(c Caller, n int) (bool, time.Duration) {
+	if c.RequestsPerSecond <= 0 {
+		return true, 0
+	}
+	burst := float64(c.BurstSize())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[c.Name]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[c.Name] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*c.RequestsPerSecond)
+	b.last = now
+	if float64(n) > burst {
+		return false, 0
+	}
+	if b.tokens < float64(n) {
+		return false, time.Duration((float64(n) - b.tokens) / c.RequestsPerSecond * float64(time.Second))
+	}
+	b.tokens -= float64(n)
+	return true, 0
+}
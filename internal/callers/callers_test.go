@@ -0,0 +1,71 @@
+package callers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	Assert := assert.New(t)
+	now := time.Unix(1700000000, 0)
+	limiter := NewLimiter()
+	limiter.now = func() time.Time { return now }
+	billing := Caller{Name: "billing", RequestsPerSecond: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow(billing)
+		Assert.True(ok, "call %d is within burst", i)
+	}
+	ok, wait := limiter.Allow(billing)
+	Assert.False(ok)
+	Assert.Equal(500*time.Millisecond, wait)
+
+	// bucket refills at RequestsPerSecond, but not over Burst
+	now = now.Add(250 * time.Millisecond)
+	ok, wait = limiter.Allow(billing)
+	Assert.False(ok)
+	Assert.Equal(250*time.Millisecond, wait)
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ = limiter.Allow(billing)
+		Assert.True(ok)
+	}
+	ok, _ = limiter.Allow(billing)
+	Assert.False(ok)
+
+	// other callers have their own bucket, burst defaults to RequestsPerSecond and 0 doesn't limit
+	ok, _ = limiter.Allow(Caller{Name: "admin", RequestsPerSecond: 1})
+	Assert.True(ok)
+	ok, _ = limiter.Allow(Caller{Name: "admin", RequestsPerSecond: 1})
+	Assert.False(ok)
+	for i := 0; i < 100; i++ {
+		ok, _ = limiter.Allow(Caller{Name: "unlimited"})
+		Assert.True(ok)
+	}
+}
+
+func TestLimiter_AllowN(t *testing.T) {
+	Assert := assert.New(t)
+	now := time.Unix(1700000000, 0)
+	limiter := NewLimiter()
+	limiter.now = func() time.Time { return now }
+	billing := Caller{Name: "billing", RequestsPerSecond: 2, Burst: 5}
+
+	ok, _ := limiter.AllowN(billing, 4)
+	Assert.True(ok)
+	ok, wait := limiter.AllowN(billing, 3)
+	Assert.False(ok, "only one call is left")
+	Assert.Equal(time.Second, wait)
+	ok, _ = limiter.Allow(billing)
+	Assert.True(ok)
+
+	// more calls than the bucket holds are never allowed
+	now = now.Add(time.Hour)
+	ok, _ = limiter.AllowN(billing, 6)
+	Assert.False(ok)
+	Assert.Equal(5, billing.BurstSize())
+	Assert.Equal(2, Caller{RequestsPerSecond: 1.5}.BurstSize())
+	Assert.Equal(0, Caller{}.BurstSize())
+}
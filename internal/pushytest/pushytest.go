@@ -0,0 +1,268 @@
+// Package pushytest has fakes of pushy shared by tests of pushy and its commands.
+// it doesn't import pushy, so tests inside package pushy can use it as well
+package pushytest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// responses pushy answers with, keyed by method and path
+var responses = map[string]string{
+	"GET /devices/DEVICE":       `{"device":{"date":1445207358,"platform":"android"},"subscriptions":["news"],"presence":{"online":true,"last_active":{"date":1464006925,"seconds_ago":215}},"pending_notifications":[{"id":"ID","date":1464008196,"payload":{"message":"Hello World!"},"expiration":1466600196}]}`,
+	"POST /devices/presence":    `{"presence":[{"id":"DEVICE","online":false,"last_active":1429406442}]}`,
+	"GET /pushes/PUSH_ID":       `{"push":{"date":1464003935,"payload":{"message":"Hello World!"},"expiration":1466595935,"pending_devices":["DEVICE"]}}`,
+	"DELETE /pushes/PUSH_ID":    `{"success":true}`,
+	"POST /devices/subscribe":   `{"success":true}`,
+	"POST /devices/unsubscribe": `{"success":true}`,
+	"GET /topics":               `{"topics":[{"name":"news","subscribers":3}]}`,
+}
+
+// Request is a request the Server received
+type Request struct {
+	Method string
+	Path   string
+	APIKey string
+	Header http.Header
+	// Encoding is Content-Encoding of the request, Body is decompressed
+	Encoding string
+	Body     []byte
+	// Status is the status it was answered with, 0 while it's being answered
+	Status int
+}
+
+// String is method, path and body of the request
+func (r Request) String() string {
+	return strings.TrimSpace(r.Method + " " + r.Path + " " + strings.TrimSpace(string(r.Body)))
+}
+
+// Decode decodes json body of the request into v
+func (r Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a fake pushy, it records requests and answers them like pushy does.
+// device DEVICE and notification PUSH_ID exist, every notification sent gets id PUSH1, PUSH2...
+// it's safe to use from multiple goroutines
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	apiKey     string
+	status     int
+	delay      time.Duration
+	rejectGzip bool
+	reject     func(Request) bool
+	release    chan struct{}
+	requests   []Request
+	pushes     []Request
+	active     int
+	maxActive  int
+}
+
+// NewServer starts a Server which accepts any api key, close it once done
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// SetAPIKey makes the server reject requests with another api key with 401, "" accepts any key
+func (s *Server) SetAPIKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = key
+}
+
+// SetStatus makes the server answer every request with status and error "unavailable",
+// a status below 400 answers requests normally
+func (s *Server) SetStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// SetDelay makes the server wait before answering, unless the client gives up first
+func (s *Server) SetDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+}
+
+// RejectGzip makes the server answer compressed requests with 415
+func (s *Server) RejectGzip(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectGzip = reject
+}
+
+// Reject makes the server answer requests for which reject returns true with 400 and error "rejected"
+func (s *Server) Reject(reject func(Request) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject = reject
+}
+
+// Block holds requests until release is called
+func (s *Server) Block() (release func()) {
+	ch := make(chan struct{})
+	s.mu.Lock()
+	s.release = ch
+	s.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(ch) })
+	}
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Pushes returns notifications which were sent successfully, in the order their ids were given
+func (s *Server) Pushes() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.pushes...)
+}
+
+// MaxActive returns the most requests which were being answered at once
+func (s *Server) MaxActive() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxActive
+}
+
+// Reset forgets received requests
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests, s.pushes, s.maxActive = nil, nil, 0
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	request := Request{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		APIKey:   r.URL.Query().Get("api_key"),
+		Header:   r.Header,
+		Encoding: r.Header.Get("Content-Encoding"),
+	}
+	var body io.Reader = r.Body
+	if request.Encoding == "gzip" {
+		if reader, err := gzip.NewReader(r.Body); err == nil {
+			body = reader
+		}
+	}
+	request.Body, _ = ioutil.ReadAll(body)
+
+	s.mu.Lock()
+	index := len(s.requests)
+	s.requests = append(s.requests, request)
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+	release, delay := s.release, s.delay
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	if release != nil {
+		<-release
+	}
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+		return
+	}
+
+	s.mu.Lock()
+	status, response := s.respond(request)
+	s.requests[index].Status = status
+	s.mu.Unlock()
+	w.WriteHeader(status)
+	w.Write([]byte(response))
+}
+
+// respond decides how request is answered, it's called with mu held
+func (s *Server) respond(request Request) (int, string) {
+	switch {
+	case s.apiKey != "" && request.APIKey != s.apiKey:
+		return http.StatusUnauthorized, `{"error":"invalid api key"}`
+	case s.status >= 400:
+		return s.status, `{"error":"unavailable"}`
+	case s.rejectGzip && request.Encoding == "gzip":
+		return http.StatusUnsupportedMediaType, `{"error":"unsupported content encoding"}`
+	case s.reject != nil && s.reject(request):
+		return http.StatusBadRequest, `{"error":"rejected"}`
+	}
+	status := http.StatusOK
+	if request.Method == http.MethodPost && request.Path == "/push" {
+		var push struct {
+			To []string `json:"to"`
+		}
+		if err := request.Decode(&push); err != nil {
+			return http.StatusBadRequest, fmt.Sprintf(`{"error":%q}`, err.Error())
+		}
+		request.Status = status
+		s.pushes = append(s.pushes, request)
+		return status, fmt.Sprintf(`{"success":true,"id":"PUSH%d","info":{"devices":%d}}`, len(s.pushes), len(push.To))
+	}
+	response, ok := responses[request.Method+" "+request.Path]
+	if !ok {
+		return http.StatusNotFound, `{"error":"not found"}`
+	}
+	return status, response
+}
+
+// Credentials is a pushy.CredentialRefresher which moves on to the next of Tokens every time it's refreshed,
+// the last token is kept once they run out
+type Credentials struct {
+	Tokens []string
+
+	mu        sync.Mutex
+	refreshed int
+}
+
+// APIToken returns the current token
+func (c *Credentials) APIToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.Tokens) == 0 {
+		return "", nil
+	}
+	if c.refreshed >= len(c.Tokens) {
+		return c.Tokens[len(c.Tokens)-1], nil
+	}
+	return c.Tokens[c.refreshed], nil
+}
+
+// Refresh moves on to the next token
+func (c *Credentials) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshed++
+	return nil
+}
+
+// Refreshed returns how many times Refresh was called
+func (c *Credentials) Refreshed() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refreshed
+}
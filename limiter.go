@@ -0,0 +1,99 @@
+package pushy
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// LimiterConfig configures a Limiter, zero values don't limit
+type LimiterConfig struct {
+	// RequestsPerSecond limits how often requests are started
+	RequestsPerSecond float64
+	// MaxConcurrent limits how many requests run at once, a request runs until its response body is closed
+	MaxConcurrent int
+}
+
+// Limiter is an IHTTPClient which paces requests and limits how many of them run at once,
+// so a single app can't use up pushy's rate limit or connections shared with others.
+// requests wait for their turn until their context is done (see WithTimeout)
+//  sdk.SetHTTPClient(pushy.NewLimiter(pushy.GetDefaultHTTPClient(10*time.Second), pushy.LimiterConfig{RequestsPerSecond: 50}))
+// it's safe to use from multiple goroutines
+type Limiter struct {
+	client IHTTPClient
+	pace   *pacer
+	slots  chan struct{}
+}
+
+// NewLimiter wraps client with limits of config
+func NewLimiter(client IHTTPClient, config LimiterConfig) *Limiter {
+	l := &Limiter{
+		client: client,
+		pace:   newPacer(config.RequestsPerSecond),
+	}
+	if config.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return l
+}
+
+// Get makes a GET request once limits allow it
+func (l *Limiter) Get(url string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return l.Do(request)
+}
+
+// Post makes a POST request once limits allow it
+func (l *Limiter) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	return l.Do(request)
+}
+
+// Do sends request once limits allow it
+func (l *Limiter) Do(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err := l.pace.wait(ctx); err != nil {
+		l.release()
+		return nil, err
+	}
+	response, err := l.client.Do(request)
+	if err != nil {
+		l.release()
+		return response, err
+	}
+	response.Body = &releaseOnClose{ReadCloser: response.Body, release: l.release}
+	return response, nil
+}
+
+func (l *Limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// releaseOnClose gives back the slot of a request once its body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
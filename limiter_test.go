@@ -0,0 +1,62 @@
+package pushy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	release := server.Block()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.NewLimiter(pushy.GetDefaultHTTPClient(time.Second), pushy.LimiterConfig{MaxConcurrent: 2}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sdk.NotificationStatus("PUSH_ID")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	Assert.Equal(2, len(server.Requests()), "others wait for a slot")
+
+	// waiting stops with the timeout of the call
+	_, _, err := sdk.Topics(pushy.WithTimeout(20 * time.Millisecond))
+	Assert.Contains(err.Error(), context.DeadlineExceeded.Error())
+
+	release()
+	wg.Wait()
+	Assert.Equal(5, len(server.Requests()))
+	Assert.Equal(2, server.MaxActive())
+}
+
+func TestLimiter_RequestsPerSecond(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.NewLimiter(pushy.GetDefaultHTTPClient(time.Second), pushy.LimiterConfig{RequestsPerSecond: 50}))
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sdk.DeviceInfo("DEVICE")
+		}()
+	}
+	wg.Wait()
+	Assert.Equal(5, len(server.Requests()))
+	Assert.True(time.Since(started) >= 80*time.Millisecond, "requests are 20ms apart")
+}
@@ -0,0 +1,280 @@
+package pushy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// Catalog provides localized format strings, formats use the same placeholders as iOS
+// Localizable.strings do (%@, %d, positional %1$@ and so on), so they can be shipped with the app as well
+type Catalog interface {
+	Message(locale string, key string) (string, bool)
+}
+
+// CatalogFunc adapts a function to Catalog, it can be used to plug in other catalogs
+type CatalogFunc func(locale string, key string) (string, bool)
+
+// Message calls f(locale, key)
+func (f CatalogFunc) Message(locale string, key string) (string, bool) {
+	return f(locale, key)
+}
+
+// TextCatalog adapts a golang.org/x/text catalog to Catalog, locales are parsed as BCP 47 tags
+// and ones which can't be parsed are looked up in fallback. messages are returned unformatted,
+// the ones selecting on arguments (plurals and such) return their default case
+func TextCatalog(c catalog.Catalog, fallback language.Tag) Catalog {
+	return textCatalog{catalog: c, fallback: fallback}
+}
+
+type textCatalog struct {
+	catalog  catalog.Catalog
+	fallback language.Tag
+}
+
+// Message returns format of key in the catalog for locale or its parents
+func (c textCatalog) Message(locale string, key string) (string, bool) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		tag = c.fallback
+	}
+	var format formatRecorder
+	if err := c.catalog.Context(tag, &format).Execute(key); err != nil {
+		return "", false
+	}
+	return format.format, true
+}
+
+// formatRecorder keeps what a catalog message renders to, without formatting it
+type formatRecorder struct {
+	format string
+}
+
+func (r *formatRecorder) Render(s string) {
+	r.format += s
+}
+
+func (r *formatRecorder) Arg(i int) interface{} {
+	return nil
+}
+
+// MapCatalog is a Catalog backed by a map of locale => key => format
+type MapCatalog map[string]map[string]string
+
+// Message returns format for key in locale
+func (c MapCatalog) Message(locale string, key string) (string, bool) {
+	message, ok := c[locale][key]
+	return message, ok
+}
+
+// LoadCatalog reads a MapCatalog from json shaped like {"en": {"greeting": "Hello %@"}}
+func LoadCatalog(r io.Reader) (MapCatalog, error) {
+	var catalog MapCatalog
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// LocalizedMessage is a notification expressed as catalog keys and arguments
+type LocalizedMessage struct {
+	TitleKey  string
+	TitleArgs []string
+	BodyKey   string
+	BodyArgs  []string
+}
+
+// Localizer keeps IOSNotification loc keys consistent with a Catalog,
+// it can either emit loc key notifications (rendered on device) or render them on server per locale
+type Localizer struct {
+	Catalog       Catalog
+	DefaultLocale string
+}
+
+// NewLocalizer creates a Localizer, defaultLocale is used to validate loc key notifications
+// and as fallback when a locale doesn't have a message
+func NewLocalizer(catalog Catalog, defaultLocale string) *Localizer {
+	return &Localizer{
+		Catalog:       catalog,
+		DefaultLocale: defaultLocale,
+	}
+}
+
+// Validate checks that keys of message exist in locale and number of args match placeholders
+func (l *Localizer) Validate(locale string, message LocalizedMessage) error {
+	if message.TitleKey == "" && message.BodyKey == "" {
+		return ValidationError{Field: "notification.loc_key", Reason: "at least one of title or body key is required"}
+	}
+	if _, err := l.format(locale, "notification.title_loc_key", message.TitleKey, message.TitleArgs); err != nil {
+		return err
+	}
+	_, err := l.format(locale, "notification.loc_key", message.BodyKey, message.BodyArgs)
+	return err
+}
+
+// Notification returns a loc key notification after validating it against default locale
+func (l *Localizer) Notification(message LocalizedMessage) (IOSNotification, error) {
+	if err := l.Validate(l.DefaultLocale, message); err != nil {
+		return IOSNotification{}, err
+	}
+	return IOSNotification{
+		LocKey:       message.BodyKey,
+		LocArgs:      message.BodyArgs,
+		TitleLocKey:  message.TitleKey,
+		TitleLocArgs: message.TitleArgs,
+	}, nil
+}
+
+// Render renders title and body for locale on the server, falls back to default locale
+func (l *Localizer) Render(locale string, message LocalizedMessage) (IOSNotification, error) {
+	if err := l.Validate(locale, message); err != nil {
+		return IOSNotification{}, err
+	}
+	title, _ := l.format(locale, "notification.title_loc_key", message.TitleKey, message.TitleArgs)
+	body, _ := l.format(locale, "notification.loc_key", message.BodyKey, message.BodyArgs)
+	return IOSNotification{Title: title, Body: body}, nil
+}
+
+// RenderByLocale renders message once for every locale in recipients (locale => device tokens)
+// and returns a copy of request for each of them, sorted by locale
+func (l *Localizer) RenderByLocale(request SendNotificationRequest, recipients map[string][]string, message LocalizedMessage) ([]SendNotificationRequest, error) {
+	locales := make([]string, 0, len(recipients))
+	for locale := range recipients {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	requests := make([]SendNotificationRequest, 0, len(locales))
+	for _, locale := range locales {
+		if len(recipients[locale]) == 0 {
+			continue
+		}
+		rendered, err := l.Render(locale, message)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", locale, err)
+		}
+		localized := request
+		localized.To = recipients[locale]
+		notification := request.IOSNotification
+		notification.Title = rendered.Title
+		notification.Body = rendered.Body
+		localized.IOSNotification = notification
+		requests = append(requests, localized)
+	}
+	return requests, nil
+}
+
+// format looks up key and renders it with args, empty key renders empty string
+func (l *Localizer) format(locale string, field string, key string, args []string) (string, error) {
+	if key == "" {
+		if len(args) != 0 {
+			return "", ValidationError{Field: field, Reason: "args given without a key"}
+		}
+		return "", nil
+	}
+	message, ok := l.Catalog.Message(locale, key)
+	if !ok && locale != l.DefaultLocale {
+		message, ok = l.Catalog.Message(l.DefaultLocale, key)
+	}
+	if !ok {
+		return "", ValidationError{Field: field, Reason: fmt.Sprintf("key %q doesn't exist in locale %q", key, locale)}
+	}
+	if expected := placeholderCount(message); expected != len(args) {
+		return "", ValidationError{Field: field, Reason: fmt.Sprintf("key %q expects %d args, got %d", key, expected, len(args))}
+	}
+	return renderFormat(message, args), nil
+}
+
+// placeholder matches %% or format specifiers of iOS (%@, %1$@) and go (%s, %[1]s)
+// with flags, width, precision and length modifiers, like %-10s, %5d, %.1f or %ld
+var placeholder = regexp.MustCompile(`%%|%(?:([1-9][0-9]*)\$)?([-+ 0#]*\d*(?:\.\d+)?)(?:\[([1-9][0-9]*)\])?(?:hh|h|ll|l|q|L|z|t|j)?([@dDiuUxXoOfFeEgGaAcCsSpv])`)
+
+// position returns the 1 based argument index of a placeholder match or 0 if it takes the next one
+func position(format string, match []int) int {
+	for _, group := range []int{2, 6} {
+		if match[group] != -1 {
+			index, _ := strconv.Atoi(format[match[group]:match[group+1]])
+			return index
+		}
+	}
+	return 0
+}
+
+func placeholderCount(format string) int {
+	sequential, positional := 0, 0
+	for _, match := range placeholder.FindAllStringSubmatchIndex(format, -1) {
+		if format[match[0]:match[1]] == "%%" {
+			continue
+		}
+		index := position(format, match)
+		if index == 0 {
+			sequential++
+			continue
+		}
+		if index > positional {
+			positional = index
+		}
+	}
+	if positional > sequential {
+		return positional
+	}
+	return sequential
+}
+
+func renderFormat(format string, args []string) string {
+	next := 0
+	var out strings.Builder
+	last := 0
+	for _, match := range placeholder.FindAllStringSubmatchIndex(format, -1) {
+		out.WriteString(format[last:match[0]])
+		last = match[1]
+		if format[match[0]:match[1]] == "%%" {
+			out.WriteString("%")
+			continue
+		}
+		index := position(format, match) - 1
+		if index < 0 {
+			index = next
+			next++
+		}
+		if index < len(args) {
+			out.WriteString(renderArg(format[match[4]:match[5]], format[match[8]], args[index]))
+		}
+	}
+	out.WriteString(format[last:])
+	return out.String()
+}
+
+// renderArg applies flags, width and precision of a placeholder to arg,
+// numbers are formatted as numbers when arg is one and everything else is padded as a string
+func renderArg(spec string, conversion byte, arg string) string {
+	if spec == "" {
+		return arg
+	}
+	switch conversion {
+	case 'd', 'D', 'i', 'u', 'U':
+		conversion = 'd'
+	case 'o', 'O':
+		conversion = 'o'
+	case 'F':
+		conversion = 'f'
+	}
+	switch conversion {
+	case 'd', 'o', 'x', 'X':
+		if value, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			return fmt.Sprintf("%"+spec+string(conversion), value)
+		}
+	case 'f', 'e', 'E', 'g', 'G':
+		if value, err := strconv.ParseFloat(arg, 64); err == nil {
+			return fmt.Sprintf("%"+spec+string(conversion), value)
+		}
+	}
+	width := strings.SplitN(spec, ".", 2)[0]
+	return fmt.Sprintf("%"+width+"s", arg)
+}
@@ -0,0 +1,202 @@
+package pushy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+const catalogJSON = `{
+	"en": {
+		"order_shipped_title": "Order shipped",
+		"order_shipped": "Hi %@, order %@ is on its way",
+		"discount": "%d%% off for %2$@",
+		"positional": "%2$@ and %1$@",
+		"rating": "%.1f stars from %5d reviews",
+		"padded": "[%-6@] [%6@] %ld left",
+		"positional_precision": "%2$.2f for %1$@"
+	},
+	"de": {
+		"order_shipped_title": "Bestellung versandt",
+		"order_shipped": "Hallo %1$@, Bestellung %2$@ ist unterwegs"
+	}
+}`
+
+func getLocalizer(t *testing.T) *pushy.Localizer {
+	catalog, err := pushy.LoadCatalog(strings.NewReader(catalogJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pushy.NewLocalizer(catalog, "en")
+}
+
+func TestLoadCatalog(t *testing.T) {
+	_, err := pushy.LoadCatalog(strings.NewReader(`{"en": "not a map"}`))
+	assert.NotNil(t, err)
+}
+
+func TestLocalizer_Validate(t *testing.T) {
+	localizer := getLocalizer(t)
+	table := []struct {
+		message pushy.LocalizedMessage
+		field   string
+	}{
+		{message: pushy.LocalizedMessage{}, field: "notification.loc_key"},
+		{message: pushy.LocalizedMessage{BodyKey: "missing"}, field: "notification.loc_key"},
+		{message: pushy.LocalizedMessage{BodyKey: "order_shipped", BodyArgs: []string{"Sam"}}, field: "notification.loc_key"},
+		{message: pushy.LocalizedMessage{BodyKey: "discount", BodyArgs: []string{"10"}}, field: "notification.loc_key"},
+		{message: pushy.LocalizedMessage{TitleKey: "order_shipped_title", TitleArgs: []string{"x"}}, field: "notification.title_loc_key"},
+		{message: pushy.LocalizedMessage{TitleArgs: []string{"x"}, BodyKey: "positional", BodyArgs: []string{"a", "b"}}, field: "notification.title_loc_key"},
+	}
+	for _, data := range table {
+		err := localizer.Validate("en", data.message)
+		validationErr, ok := err.(pushy.ValidationError)
+		if assert.True(t, ok, data.field) {
+			assert.Equal(t, data.field, validationErr.Field)
+		}
+	}
+	assert.Nil(t, localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "discount", BodyArgs: []string{"10", "members"}}))
+	// width and precision don't hide placeholders
+	assert.Nil(t, localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "rating", BodyArgs: []string{"4.25", "12"}}))
+	assert.Nil(t, localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "padded", BodyArgs: []string{"a", "b", "3"}}))
+	assert.Nil(t, localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "positional_precision", BodyArgs: []string{"tea", "2.5"}}))
+	assert.NotNil(t, localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "rating", BodyArgs: []string{"4.25"}}))
+}
+
+func TestLocalizer_Notification(t *testing.T) {
+	Assert := assert.New(t)
+	localizer := getLocalizer(t)
+	notification, err := localizer.Notification(pushy.LocalizedMessage{
+		TitleKey: "order_shipped_title",
+		BodyKey:  "order_shipped",
+		BodyArgs: []string{"Sam", "#42"},
+	})
+	Assert.Nil(err)
+	Assert.Equal("order_shipped", notification.LocKey)
+	Assert.Equal([]string{"Sam", "#42"}, notification.LocArgs)
+	Assert.Equal("order_shipped_title", notification.TitleLocKey)
+	Assert.Empty(notification.Title)
+	Assert.Empty(notification.Body)
+
+	_, err = localizer.Notification(pushy.LocalizedMessage{BodyKey: "order_shipped"})
+	Assert.NotNil(err)
+}
+
+func TestLocalizer_Render(t *testing.T) {
+	Assert := assert.New(t)
+	localizer := getLocalizer(t)
+	table := []struct {
+		locale  string
+		message pushy.LocalizedMessage
+		title   string
+		body    string
+	}{
+		{
+			locale:  "de",
+			message: pushy.LocalizedMessage{TitleKey: "order_shipped_title", BodyKey: "order_shipped", BodyArgs: []string{"Sam", "#42"}},
+			title:   "Bestellung versandt",
+			body:    "Hallo Sam, Bestellung #42 ist unterwegs",
+		},
+		{
+			locale:  "de",
+			message: pushy.LocalizedMessage{BodyKey: "discount", BodyArgs: []string{"10", "members"}},
+			body:    "10% off for members",
+		},
+		{
+			locale:  "fr",
+			message: pushy.LocalizedMessage{BodyKey: "positional", BodyArgs: []string{"a", "b"}},
+			body:    "b and a",
+		},
+		{
+			locale:  "en",
+			message: pushy.LocalizedMessage{BodyKey: "rating", BodyArgs: []string{"4.25", "12"}},
+			body:    "4.2 stars from    12 reviews",
+		},
+		{
+			locale:  "en",
+			message: pushy.LocalizedMessage{BodyKey: "padded", BodyArgs: []string{"a", "b", "3"}},
+			body:    "[a     ] [     b] 3 left",
+		},
+		{
+			locale:  "en",
+			message: pushy.LocalizedMessage{BodyKey: "positional_precision", BodyArgs: []string{"tea", "2.5"}},
+			body:    "2.50 for tea",
+		},
+		{
+			locale:  "en",
+			message: pushy.LocalizedMessage{BodyKey: "rating", BodyArgs: []string{"many", "some"}},
+			body:    "many stars from  some reviews",
+		},
+	}
+	for _, data := range table {
+		notification, err := localizer.Render(data.locale, data.message)
+		Assert.Nil(err)
+		Assert.Equal(data.title, notification.Title)
+		Assert.Equal(data.body, notification.Body)
+	}
+}
+
+func TestLocalizer_RenderByLocale(t *testing.T) {
+	Assert := assert.New(t)
+	localizer := getLocalizer(t)
+	request := pushy.SendNotificationRequest{
+		Data:            `{"order":"42"}`,
+		IOSNotification: pushy.IOSNotification{Sound: "default"},
+	}
+	message := pushy.LocalizedMessage{TitleKey: "order_shipped_title", BodyKey: "order_shipped", BodyArgs: []string{"Sam", "#42"}}
+	requests, err := localizer.RenderByLocale(request, map[string][]string{
+		"en": {"A", "B"},
+		"de": {"C"},
+		"fr": {},
+	}, message)
+	Assert.Nil(err)
+	Assert.Len(requests, 2)
+	Assert.Equal([]string{"C"}, requests[0].To)
+	Assert.Equal("Bestellung versandt", requests[0].IOSNotification.Title)
+	Assert.Equal("default", requests[0].IOSNotification.Sound)
+	Assert.Equal([]string{"A", "B"}, requests[1].To)
+	Assert.Equal("Hi Sam, order #42 is on its way", requests[1].IOSNotification.Body)
+	Assert.Equal(`{"order":"42"}`, requests[1].Data)
+
+	_, err = localizer.RenderByLocale(request, map[string][]string{"en": {"A"}}, pushy.LocalizedMessage{BodyKey: "missing"})
+	Assert.Contains(err.Error(), "en")
+}
+
+func TestCatalogFunc(t *testing.T) {
+	catalog := pushy.CatalogFunc(func(locale string, key string) (string, bool) {
+		return strings.ToUpper(key) + " %@", key != ""
+	})
+	notification, err := pushy.NewLocalizer(catalog, "en").Render("en", pushy.LocalizedMessage{BodyKey: "hi", BodyArgs: []string{"there"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "HI there", notification.Body)
+}
+
+func TestTextCatalog(t *testing.T) {
+	Assert := assert.New(t)
+	builder := catalog.NewBuilder()
+	builder.SetString(language.English, "greeting", "Hello %s")
+	builder.SetString(language.German, "greeting", "Hallo %[1]s")
+	builder.Set(language.English, "unread", plural.Selectf(1, "%d",
+		plural.One, "%d unread message",
+		plural.Other, "%d unread messages",
+	))
+	localizer := pushy.NewLocalizer(pushy.TextCatalog(builder, language.English), "en")
+
+	notification, err := localizer.Render("de-AT", pushy.LocalizedMessage{BodyKey: "greeting", BodyArgs: []string{"Sam"}})
+	Assert.Nil(err)
+	Assert.Equal("Hallo Sam", notification.Body)
+	notification, err = localizer.Render("not a locale", pushy.LocalizedMessage{BodyKey: "greeting", BodyArgs: []string{"Sam"}})
+	Assert.Nil(err)
+	Assert.Equal("Hello Sam", notification.Body)
+	notification, err = localizer.Render("en", pushy.LocalizedMessage{BodyKey: "unread", BodyArgs: []string{"3"}})
+	Assert.Nil(err)
+	Assert.Equal("3 unread messages", notification.Body)
+
+	Assert.NotNil(localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "missing"}))
+	Assert.NotNil(localizer.Validate("en", pushy.LocalizedMessage{BodyKey: "greeting"}))
+}
@@ -0,0 +1,216 @@
+package pushy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CallOption changes how a single operation is made
+//  var meta pushy.ResponseMeta
+//  sdk.NotifyDeviceWithOptions(request, pushy.WithMeta(&meta))
+//  log.Printf("sent, request id %s", meta.RequestID)
+type CallOption func(*CallOptions)
+
+// CallOptions are the options an operation was called with,
+// IPushyClientWithOptions implementations read them with NewCallOptions
+type CallOptions struct {
+	// Meta is filled with metadata of the response when it isn't nil
+	Meta *ResponseMeta
+	// Timeout limits the whole operation, retries included, 0 leaves it to the http client
+	Timeout time.Duration
+	// Retry is the policy failed requests are retried with, nil doesn't retry
+	Retry *RetryPolicy
+	// IdempotencyKey is sent as Idempotency-Key header
+	IdempotencyKey string
+	// Header is added to every request of the operation
+	Header http.Header
+}
+
+// NewCallOptions applies opts in order
+func NewCallOptions(opts ...CallOption) CallOptions {
+	var options CallOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithMeta fills meta once the operation is done, it's filled even when the operation fails,
+// as long as pushy responded
+func WithMeta(meta *ResponseMeta) CallOption {
+	return func(options *CallOptions) {
+		options.Meta = meta
+	}
+}
+
+// WithTimeout limits how long the operation may take, including retries
+//  sdk.DeviceInfoWithOptions(deviceID, pushy.WithTimeout(500*time.Millisecond))
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(options *CallOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithRetry retries failed requests of the operation according to policy, zero fields of policy are defaults.
+// a request which failed may still have reached pushy, retry NotifyDeviceWithOptions with an idempotency key
+//  sdk.NotifyDeviceWithOptions(request, pushy.WithRetry(pushy.RetryPolicy{Attempts: 5}), pushy.WithIdempotencyKey(eventID))
+func WithRetry(policy RetryPolicy) CallOption {
+	if policy.Attempts <= 0 {
+		policy.Attempts = 3
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+	if policy.RetryOn == nil {
+		policy.RetryOn = DefaultIsFailure
+	}
+	return func(options *CallOptions) {
+		options.Retry = &policy
+	}
+}
+
+// WithIdempotencyKey sends key as Idempotency-Key header, so the operation can be told apart from its retries
+func WithIdempotencyKey(key string) CallOption {
+	return func(options *CallOptions) {
+		options.IdempotencyKey = key
+	}
+}
+
+// WithHeader adds a header to requests of the operation, it can be used multiple times.
+// Content-Type and Content-Encoding are set by pushy and can't be changed
+func WithHeader(key string, value string) CallOption {
+	return func(options *CallOptions) {
+		if options.Header == nil {
+			options.Header = http.Header{}
+		}
+		options.Header.Add(key, value)
+	}
+}
+
+// RetryPolicy decides which requests are retried and how long to wait before retrying them
+type RetryPolicy struct {
+	// Attempts is the most requests made, the first one included. default is 3
+	Attempts int
+	// Backoff is waited before the first retry and doubles before each next one, default is 100ms.
+	// when pushy responds with Retry-After it's waited instead
+	Backoff time.Duration
+	// MaxBackoff is the longest wait between requests, default is 10s
+	MaxBackoff time.Duration
+	// RetryOn decides if a request is retried, default is DefaultIsFailure. ErrCircuitOpen is never retried
+	RetryOn func(response *http.Response, err error) bool
+}
+
+// backoff tells how long to wait before the attempt after attempt, false when the operation shouldn't be retried
+func (c *call) backoff(ctx context.Context, attempt int) (time.Duration, bool) {
+	policy := c.options.Retry
+	// nothing was sent when request couldn't even be encoded
+	if policy == nil || attempt >= policy.Attempts || ctx.Err() != nil || (c.response == nil && c.err == nil) {
+		return 0, false
+	}
+	// an open circuit fails fast on purpose, retrying would only wait for it to fail again
+	if errors.Is(c.err, ErrCircuitOpen) || !policy.RetryOn(c.response, c.err) {
+		return 0, false
+	}
+	wait := policy.Backoff
+	for i := 1; i < attempt && wait < policy.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if c.response != nil {
+		if seconds := headerInt(c.response.Header, headerRetryAfter); seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+	}
+	if wait > policy.MaxBackoff {
+		wait = policy.MaxBackoff
+	}
+	return wait, true
+}
+
+// ResponseMeta is what pushy sent along with a response, useful to correlate requests with pushy support
+type ResponseMeta struct {
+	// StatusCode and Header are those of the last response, 0 and nil if pushy never responded
+	StatusCode int
+	Header     http.Header
+	// RequestID is the id pushy assigned to the request, RequestID and RateLimit are read from MetaHeaders
+	RequestID string
+	RateLimit RateLimit
+	// Latency is the time the whole operation took, retries included
+	Latency time.Duration
+	// Attempts is the number of requests which were sent
+	Attempts int
+}
+
+// RateLimit is the rate limit state reported by pushy, fields are zero when pushy didn't report them
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// MetaHeaders are the response headers ResponseMeta reads request id and rate limit from.
+// pushy's api reference doesn't document such headers, the defaults are the names commonly used for them,
+// change them with SetMetaHeaders when pushy (or a proxy in front of it) reports them differently.
+// empty names aren't read
+type MetaHeaders struct {
+	RequestID          string
+	RateLimitLimit     string
+	RateLimitRemaining string
+	// RateLimitReset is read as unix time in seconds
+	RateLimitReset string
+}
+
+// DefaultMetaHeaders returns header names used unless SetMetaHeaders was called
+func DefaultMetaHeaders() MetaHeaders {
+	return MetaHeaders{
+		RequestID:          "X-Request-Id",
+		RateLimitLimit:     "X-RateLimit-Limit",
+		RateLimitRemaining: "X-RateLimit-Remaining",
+		RateLimitReset:     "X-RateLimit-Reset",
+	}
+}
+
+// headers pushy accepts or responds with, which are part of http
+const (
+	headerRetryAfter     = "Retry-After"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// observe records an attempt which got response (nil when it failed before pushy responded),
+// headers are the names metadata is read from
+func (m *ResponseMeta) observe(response *http.Response, headers MetaHeaders) {
+	m.Attempts++
+	if response == nil {
+		return
+	}
+	m.StatusCode = response.StatusCode
+	m.Header = response.Header
+	m.RequestID = headerString(response.Header, headers.RequestID)
+	m.RateLimit = RateLimit{
+		Limit:     headerInt(response.Header, headers.RateLimitLimit),
+		Remaining: headerInt(response.Header, headers.RateLimitRemaining),
+	}
+	if reset := headerInt(response.Header, headers.RateLimitReset); reset > 0 {
+		m.RateLimit.Reset = time.Unix(int64(reset), 0)
+	}
+}
+
+func headerString(header http.Header, key string) string {
+	if key == "" {
+		return ""
+	}
+	return header.Get(key)
+}
+
+func headerInt(header http.Header, key string) int {
+	value, err := strconv.Atoi(headerString(header, key))
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,232 @@
+package pushy_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMeta(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "REQ-"+r.Method)
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"id":"PUSH"}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+
+	var meta pushy.ResponseMeta
+	res, _, err := sdk.NotifyDeviceWithOptions(pushy.SendNotificationRequest{To: []string{"D"}}, pushy.WithMeta(&meta))
+	Assert.Nil(err)
+	Assert.Equal("PUSH", res.ID)
+	Assert.Equal(http.StatusOK, meta.StatusCode)
+	Assert.Equal("REQ-POST", meta.RequestID)
+	Assert.Equal("42", meta.Header.Get("X-RateLimit-Remaining"))
+	Assert.Equal(pushy.RateLimit{Limit: 100, Remaining: 42, Reset: time.Unix(1700000000, 0)}, meta.RateLimit)
+	Assert.Equal(1, meta.Attempts)
+	Assert.True(meta.Latency > 0)
+
+	// meta is reset for every operation and filled for failed ones as well
+	_, pushyErr, err := sdk.DeleteNotificationWithOptions("PUSH", pushy.WithMeta(&meta))
+	Assert.NotNil(err)
+	Assert.Equal("not found", pushyErr.Error)
+	Assert.Equal(http.StatusNotFound, meta.StatusCode)
+	Assert.Equal("REQ-DELETE", meta.RequestID)
+	Assert.Equal(1, meta.Attempts)
+}
+
+func TestWithMeta_Headers(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "DEFAULT")
+		w.Header().Set("X-Trace-Id", "TRACE")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("RateLimit-Remaining", "7")
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	sdk.SetMetaHeaders(pushy.MetaHeaders{RequestID: "X-Trace-Id", RateLimitRemaining: "RateLimit-Remaining"})
+
+	var meta pushy.ResponseMeta
+	_, _, err := sdk.DeleteNotificationWithOptions("PUSH", pushy.WithMeta(&meta))
+	Assert.Nil(err)
+	Assert.Equal("TRACE", meta.RequestID)
+	Assert.Equal(pushy.RateLimit{Remaining: 7}, meta.RateLimit, "headers without a name aren't read")
+}
+
+func TestWithMeta_Attempts(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "NEW" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"expired"}`))
+			return
+		}
+		w.Write([]byte(`{"topics":[]}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("OLD", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	sdk.SetCredentialProvider(&pushytest.Credentials{Tokens: []string{"OLD", "NEW"}})
+
+	var meta pushy.ResponseMeta
+	_, _, err := sdk.Topics(pushy.WithMeta(&meta))
+	Assert.Nil(err)
+	Assert.Equal(2, meta.Attempts, "request rejected with 401 is sent again")
+	Assert.Equal(http.StatusOK, meta.StatusCode)
+	Assert.Equal(pushy.RateLimit{}, meta.RateLimit)
+
+	sdk.SetHTTPClient(&http.Client{Transport: failingTransport{}})
+	_, _, err = sdk.Topics(pushy.WithMeta(&meta))
+	Assert.NotNil(err)
+	Assert.Equal(1, meta.Attempts)
+	Assert.Equal(0, meta.StatusCode)
+	Assert.Nil(meta.Header)
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestWithTimeout(t *testing.T) {
+	Assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/devices/SLOW" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"device":{"platform":"android"}}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+
+	_, _, err := sdk.DeviceInfoWithOptions("SLOW", pushy.WithTimeout(20*time.Millisecond))
+	if Assert.NotNil(err) {
+		Assert.Contains(err.Error(), "context deadline exceeded")
+	}
+	info, _, err := sdk.DeviceInfoWithOptions("FAST", pushy.WithTimeout(time.Second))
+	Assert.Nil(err)
+	Assert.Equal(pushy.PlatformAndroid, info.Device.Platform)
+}
+
+func TestWithRetry(t *testing.T) {
+	Assert := assert.New(t)
+	var mu sync.Mutex
+	var statuses []int
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		status := statuses[0]
+		statuses = statuses[1:]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "60")
+		}
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"id":"PUSH"}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	request := pushy.SendNotificationRequest{To: []string{"D"}}
+	retry := pushy.WithRetry(pushy.RetryPolicy{Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+
+	statuses = []int{502, 429, 200}
+	var meta pushy.ResponseMeta
+	res, pushyErr, err := sdk.NotifyDeviceWithOptions(request, retry, pushy.WithIdempotencyKey("EVENT"), pushy.WithMeta(&meta))
+	Assert.Nil(err)
+	Assert.Nil(pushyErr)
+	Assert.Equal("PUSH", res.ID)
+	Assert.Equal(3, meta.Attempts)
+	Assert.True(meta.Latency < time.Second, "Retry-After is capped by MaxBackoff")
+	Assert.Equal([]string{"EVENT", "EVENT", "EVENT"}, keys)
+
+	// gives up after Attempts and returns the last failure
+	statuses = []int{503, 503, 503, 200}
+	_, pushyErr, err = sdk.NotifyDeviceWithOptions(request, retry)
+	Assert.Contains(err.Error(), "503")
+	Assert.Equal("try again", pushyErr.Error)
+	Assert.Equal([]int{200}, statuses)
+
+	// client errors aren't retried
+	statuses = []int{400, 200}
+	_, _, err = sdk.NotifyDeviceWithOptions(request, retry)
+	Assert.Contains(err.Error(), "400")
+	Assert.Equal([]int{200}, statuses)
+
+	statuses = []int{500, 500}
+	_, _, err = sdk.NotifyDeviceWithOptions(request, pushy.WithRetry(pushy.RetryPolicy{
+		Backoff: time.Millisecond,
+		RetryOn: func(response *http.Response, err error) bool { return false },
+	}))
+	Assert.Contains(err.Error(), "500")
+	Assert.Equal([]int{500}, statuses)
+}
+
+func TestWithRetry_NetworkError(t *testing.T) {
+	Assert := assert.New(t)
+	sdk := pushy.Create("SECRET", "http://pushy.invalid")
+	sdk.SetHTTPClient(&http.Client{Transport: failingTransport{}})
+
+	var meta pushy.ResponseMeta
+	_, _, err := sdk.Topics(pushy.WithRetry(pushy.RetryPolicy{Attempts: 4, Backoff: time.Millisecond}), pushy.WithMeta(&meta))
+	Assert.Contains(err.Error(), "connection refused")
+	Assert.Equal(4, meta.Attempts)
+
+	// retries stop once the timeout is over
+	_, _, err = sdk.Topics(pushy.WithRetry(pushy.RetryPolicy{Attempts: 100, Backoff: time.Hour}), pushy.WithTimeout(20*time.Millisecond), pushy.WithMeta(&meta))
+	Assert.NotNil(err)
+	Assert.Equal(1, meta.Attempts)
+	Assert.True(meta.Latency < time.Second)
+}
+
+func TestWithHeader(t *testing.T) {
+	Assert := assert.New(t)
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+
+	_, _, err := sdk.SubscribeToTopicWithOptions("D", []string{"news"},
+		pushy.WithHeader("X-Trace", "a"),
+		pushy.WithHeader("X-Trace", "b"),
+		pushy.WithHeader("Content-Type", "text/plain"),
+	)
+	Assert.Nil(err)
+	Assert.Equal([]string{"a", "b"}, header["X-Trace"])
+	Assert.Equal("application/json", header.Get("Content-Type"))
+	Assert.Equal("", header.Get("Idempotency-Key"))
+
+	_, _, err = sdk.DeleteNotificationWithOptions("PUSH", pushy.WithHeader("X-Trace", "c"))
+	Assert.Nil(err)
+	Assert.Equal("c", header.Get("X-Trace"))
+}
@@ -0,0 +1,233 @@
+package pushy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// size limits of a notification, pushy rejects larger data and APNs rejects larger payloads
+const (
+	MaxDataSize        = 4096
+	MaxAPNsPayloadSize = 4096
+)
+
+// ErrPayloadTooLarge is matched by every PayloadTooLargeError with errors.Is
+var ErrPayloadTooLarge = errors.New("pushy: payload too large")
+
+// PayloadTooLargeError is returned for notifications which wouldn't be delivered because of their size,
+// NotifyDevice returns it without sending the request
+type PayloadTooLargeError struct {
+	// Part is either "data" or "notification" for the payload sent to APNs
+	Part  string
+	Size  int
+	Limit int
+}
+
+func (e PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("pushy: %s payload is %d bytes, limit is %d", e.Part, e.Size, e.Limit)
+}
+
+// Unwrap makes errors.Is(err, ErrPayloadTooLarge) true
+func (e PayloadTooLargeError) Unwrap() error {
+	return ErrPayloadTooLarge
+}
+
+// PayloadSize is the size of a notification as it's delivered, in bytes
+type PayloadSize struct {
+	// Data is the size of data payload delivered to android and web
+	Data int
+	// APNs is the size of payload pushy sends to APNs, aps dictionary along with data
+	APNs int
+}
+
+// SizeOf estimates size of request as it's delivered to devices, recipients don't count
+func SizeOf(request SendNotificationRequest) PayloadSize {
+	return PayloadSize{
+		Data: len(encodedData(request.Data)),
+		APNs: len(apnsPayload(request)),
+	}
+}
+
+// encodedData encodes data the way it's delivered, json is re-encoded compactly with escapes only where
+// json needs them, anything else is delivered as a json string
+func encodedData(data string) []byte {
+	if data == "" {
+		return nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		if encoded, err := marshalUnescaped(value); err == nil {
+			return encoded
+		}
+	}
+	encoded, _ := marshalUnescaped(data)
+	return encoded
+}
+
+// check returns PayloadTooLargeError when size doesn't fit the limits
+func (s PayloadSize) check() error {
+	if s.Data > MaxDataSize {
+		return PayloadTooLargeError{Part: "data", Size: s.Data, Limit: MaxDataSize}
+	}
+	if s.APNs > MaxAPNsPayloadSize {
+		return PayloadTooLargeError{Part: "notification", Size: s.APNs, Limit: MaxAPNsPayloadSize}
+	}
+	return nil
+}
+
+// apnsAlert and apnsAps are the aps dictionary of APNs, empty values are left out as they are by pushy
+type apnsAlert struct {
+	Title        string   `json:"title,omitempty"`
+	Body         string   `json:"body,omitempty"`
+	LocKey       string   `json:"loc-key,omitempty"`
+	LocArgs      []string `json:"loc-args,omitempty"`
+	TitleLocKey  string   `json:"title-loc-key,omitempty"`
+	TitleLocArgs []string `json:"title-loc-args,omitempty"`
+}
+
+type apnsAps struct {
+	Alert            *apnsAlert `json:"alert,omitempty"`
+	Badge            int        `json:"badge,omitempty"`
+	Sound            string     `json:"sound,omitempty"`
+	Category         string     `json:"category,omitempty"`
+	MutableContent   int        `json:"mutable-content,omitempty"`
+	ContentAvailable int        `json:"content-available,omitempty"`
+}
+
+// apnsPayload encodes request the way it's sent to APNs, keys of data are next to aps
+func apnsPayload(request SendNotificationRequest) []byte {
+	notification := request.IOSNotification
+	aps := apnsAps{
+		Badge:    notification.Badge,
+		Sound:    notification.Sound,
+		Category: notification.Category,
+	}
+	alert := apnsAlert{
+		Title:        notification.Title,
+		Body:         notification.Body,
+		LocKey:       notification.LocKey,
+		LocArgs:      notification.LocArgs,
+		TitleLocKey:  notification.TitleLocKey,
+		TitleLocArgs: notification.TitleLocArgs,
+	}
+	if alert.Title != "" || alert.Body != "" || alert.LocKey != "" || alert.TitleLocKey != "" {
+		aps.Alert = &alert
+	}
+	if request.IOSMutableContent {
+		aps.MutableContent = 1
+	}
+	if request.IOSContentAvailable {
+		aps.ContentAvailable = 1
+	}
+	payload := map[string]interface{}{}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(request.Data), &data); err == nil {
+		for key, value := range data {
+			payload[key] = value
+		}
+	} else if request.Data != "" {
+		payload["data"] = request.Data
+	}
+	payload["aps"] = aps
+	// APNs receives text as is, html escaping would only overestimate it
+	encoded, _ := marshalUnescaped(payload)
+	return encoded
+}
+
+// marshalUnescaped is json.Marshal without escaping of <, > and &
+func marshalUnescaped(v interface{}) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// TruncateStrategy shortens text to at most maxBytes bytes of utf-8
+type TruncateStrategy func(text string, maxBytes int) string
+
+// ellipsis ends truncated text
+const ellipsis = "…"
+
+// TruncateRunes cuts text at the last rune which fits
+func TruncateRunes(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	if maxBytes <= 0 {
+		return ""
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// TruncateEllipsis cuts text at the last rune which fits along with an ellipsis
+func TruncateEllipsis(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	if maxBytes < len(ellipsis) {
+		return TruncateRunes(text, maxBytes)
+	}
+	return TruncateRunes(text, maxBytes-len(ellipsis)) + ellipsis
+}
+
+// TruncateWords cuts text after the last whole word which fits along with an ellipsis,
+// text without spaces is cut like TruncateEllipsis does
+func TruncateWords(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	if maxBytes < len(ellipsis) {
+		return TruncateRunes(text, maxBytes)
+	}
+	cut := TruncateRunes(text, maxBytes-len(ellipsis))
+	if next, _ := utf8.DecodeRuneInString(text[len(cut):]); !unicode.IsSpace(next) {
+		if space := strings.LastIndexFunc(cut, unicode.IsSpace); space > 0 {
+			cut = cut[:space]
+		}
+	}
+	return strings.TrimRightFunc(cut, unicode.IsSpace) + ellipsis
+}
+
+// Truncate shortens body of the notification with strategy until request fits MaxAPNsPayloadSize,
+// requests which already fit are returned as they are. data isn't touched, so request with data
+// over MaxDataSize (or over the APNs limit on its own) stays a PayloadTooLargeError
+func (r SendNotificationRequest) Truncate(strategy TruncateStrategy) (SendNotificationRequest, error) {
+	size := SizeOf(r)
+	if size.Data > MaxDataSize {
+		return r, size.check()
+	}
+	if size.APNs <= MaxAPNsPayloadSize {
+		return r, nil
+	}
+	// escaping makes the payload grow faster than body, so the longest body which fits is searched for
+	body := r.IOSNotification.Body
+	fits := func(n int) bool {
+		r.IOSNotification.Body = strategy(body, n)
+		return SizeOf(r).APNs <= MaxAPNsPayloadSize
+	}
+	low, high := 0, len(body)
+	for low < high {
+		middle := (low + high + 1) / 2
+		if fits(middle) {
+			low = middle
+		} else {
+			high = middle - 1
+		}
+	}
+	r.IOSNotification.Body = strategy(body, low)
+	return r, SizeOf(r).check()
+}
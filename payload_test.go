@@ -0,0 +1,141 @@
+package pushy_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeOf(t *testing.T) {
+	Assert := assert.New(t)
+	request := pushy.SendNotificationRequest{
+		To:                []string{"D"},
+		Data:              `{"message":"<b>hi</b>"}`,
+		IOSMutableContent: true,
+		IOSNotification:   pushy.IOSNotification{Title: "Hi", Body: "Hello", Badge: 1},
+	}
+	// {"aps":{"alert":{"title":"Hi","body":"Hello"},"badge":1,"mutable-content":1},"message":"<b>hi</b>"}
+	Assert.Equal(pushy.PayloadSize{Data: 23, APNs: 99}, pushy.SizeOf(request))
+
+	// recipients don't count, escaping does
+	request.To = []string{"A", "B", "C"}
+	request.IOSNotification.Body = `"Hello"`
+	Assert.Equal(103, pushy.SizeOf(request).APNs)
+
+	Assert.Equal(pushy.PayloadSize{APNs: len(`{"aps":{}}`)}, pushy.SizeOf(pushy.SendNotificationRequest{}))
+	Assert.Equal(len(`{"aps":{},"data":"not json"}`), pushy.SizeOf(pushy.SendNotificationRequest{Data: "not json"}).APNs)
+}
+
+func TestSizeOf_DataEncoding(t *testing.T) {
+	Assert := assert.New(t)
+	// data is measured as encoded json, quotes and control characters are escaped, utf-8 isn't
+	request := pushy.SendNotificationRequest{Data: `{ "message": "say \"hi\"\nnow", "name": "Zo\u00eb", "html": "<b>" }`}
+	Assert.Equal(len(`{"html":"<b>","message":"say \"hi\"\nnow","name":"Zoë"}`), pushy.SizeOf(request).Data)
+
+	// data which isn't json is delivered as a json string
+	request.Data = "line \"one\"\n\tZoë"
+	Assert.Equal(len(`"line \"one\"\n\tZoë"`), pushy.SizeOf(request).Data)
+	Assert.Equal(len(`"{\"a\":1} x"`), pushy.SizeOf(pushy.SendNotificationRequest{Data: `{"a":1} x`}).Data)
+}
+
+func TestPayloadTooLarge(t *testing.T) {
+	Assert := assert.New(t)
+	var sent int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		w.Write([]byte(`{"success":true,"id":"PUSH"}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+
+	large := pushy.SendNotificationRequest{
+		To:              []string{"D"},
+		IOSNotification: pushy.IOSNotification{Body: strings.Repeat("a", pushy.MaxAPNsPayloadSize)},
+	}
+	res, _, err := sdk.NotifyDevice(large)
+	Assert.Nil(res)
+	Assert.True(errors.Is(err, pushy.ErrPayloadTooLarge))
+	var tooLarge pushy.PayloadTooLargeError
+	Assert.True(errors.As(err, &tooLarge))
+	Assert.Equal("notification", tooLarge.Part)
+	Assert.Equal(pushy.SizeOf(large).APNs, tooLarge.Size)
+	Assert.Equal(int32(0), atomic.LoadInt32(&sent))
+	Assert.Equal(err, large.Validate())
+
+	data := pushy.SendNotificationRequest{To: []string{"D"}, Data: `{"a":"` + strings.Repeat("a", pushy.MaxDataSize) + `"}`}
+	_, _, err = sdk.NotifyDevice(data)
+	Assert.EqualError(err, "pushy: data payload is 4104 bytes, limit is 4096")
+
+	// bodies are truncated once there's a strategy, data can't be
+	sdk.SetTruncateStrategy(pushy.TruncateEllipsis)
+	res, _, err = sdk.NotifyDevice(large)
+	Assert.Nil(err)
+	Assert.Equal("PUSH", res.ID)
+	_, _, err = sdk.NotifyDevice(data)
+	Assert.True(errors.Is(err, pushy.ErrPayloadTooLarge))
+	Assert.Equal(int32(1), atomic.LoadInt32(&sent))
+}
+
+func TestSendNotificationRequest_Truncate(t *testing.T) {
+	Assert := assert.New(t)
+	body := strings.Repeat("Grüße, \"world\" ", 400)
+	request := pushy.SendNotificationRequest{
+		Data:            `{"id":1}`,
+		IOSNotification: pushy.IOSNotification{Title: "Hi", Body: body},
+	}
+	for _, strategy := range []pushy.TruncateStrategy{pushy.TruncateRunes, pushy.TruncateEllipsis, pushy.TruncateWords} {
+		truncated, err := request.Truncate(strategy)
+		Assert.Nil(err)
+		Assert.True(pushy.SizeOf(truncated).APNs <= pushy.MaxAPNsPayloadSize)
+		Assert.True(pushy.SizeOf(truncated).APNs > pushy.MaxAPNsPayloadSize-64, "only what's needed is cut")
+		Assert.True(utf8.ValidString(truncated.IOSNotification.Body))
+		Assert.Equal("Hi", truncated.IOSNotification.Title)
+	}
+	Assert.Equal(body, request.IOSNotification.Body, "request is copied")
+
+	small := pushy.SendNotificationRequest{IOSNotification: pushy.IOSNotification{Body: "short"}}
+	truncated, err := small.Truncate(pushy.TruncateEllipsis)
+	Assert.Nil(err)
+	Assert.Equal(small, truncated)
+
+	// data over the APNs limit on its own can't be fixed by truncating body
+	data := pushy.SendNotificationRequest{
+		Data:            `{"a":"` + strings.Repeat("a", pushy.MaxDataSize-10) + `","b":"` + strings.Repeat("b", 20) + `"}`,
+		IOSNotification: pushy.IOSNotification{Body: "short"},
+	}
+	_, err = data.Truncate(pushy.TruncateEllipsis)
+	Assert.True(errors.Is(err, pushy.ErrPayloadTooLarge))
+}
+
+func TestTruncateStrategies(t *testing.T) {
+	Assert := assert.New(t)
+	text := "Grüße aus Köln"
+
+	Assert.Equal(text, pushy.TruncateRunes(text, 100))
+	Assert.Equal("Gr", pushy.TruncateRunes(text, 3), "ü isn't split")
+	Assert.Equal("Grü", pushy.TruncateRunes(text, 4))
+	Assert.Equal("", pushy.TruncateRunes(text, 0))
+
+	Assert.Equal("Grüß…", pushy.TruncateEllipsis(text, 9))
+	Assert.Equal("Gr", pushy.TruncateEllipsis(text, 2), "no room for ellipsis")
+
+	Assert.Equal("Grüße…", pushy.TruncateWords(text, 12))
+	Assert.Equal("Grüße aus…", pushy.TruncateWords(text, 15))
+	Assert.Equal("Grüß…", pushy.TruncateWords("Grüßeauskoln", 9), "a single word is cut")
+	for _, strategy := range []pushy.TruncateStrategy{pushy.TruncateRunes, pushy.TruncateEllipsis, pushy.TruncateWords} {
+		for max := 0; max <= len(text); max++ {
+			truncated := strategy(text, max)
+			Assert.True(len(truncated) <= max)
+			Assert.True(utf8.ValidString(truncated))
+		}
+	}
+}
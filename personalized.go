@@ -0,0 +1,180 @@
+package pushy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRecipientsPerRequest is the most devices pushy accepts in To of a single request
+const MaxRecipientsPerRequest = 100000
+
+// PersonalizedConfig configures SendPersonalized, zero fields are defaults
+type PersonalizedConfig struct {
+	// Concurrency is the most requests sent at once, default is 4
+	Concurrency int
+	// RequestsPerSecond limits how often requests are started, 0 doesn't limit it
+	RequestsPerSecond float64
+	// MaxRecipients is the most recipients of a single request, default is MaxRecipientsPerRequest
+	MaxRecipients int
+}
+
+// RecipientResult is the outcome of sending to a single recipient,
+// recipients which shared a request share its result
+type RecipientResult struct {
+	Response *NotificationResponse
+	PushyErr *Error
+	Err      error
+}
+
+// personalizedGroup are recipients which get the same notification
+type personalizedGroup struct {
+	content string
+	request SendNotificationRequest
+}
+
+// SendPersonalized sends every recipient (device token => notification) its own notification, To of them is ignored.
+// recipients whose notifications are the same are sent a single request, requests are sent concurrently
+// within limits of config. every recipient gets a result, those whose request wasn't sent before ctx was done
+// get ctx error and those whose notification isn't valid get the ValidationError, notifications which
+// are too large are truncated or rejected by client the way NotifyDevice does.
+// an idempotency key in opts is made unique for every request, so it's safe to use with a Deduplicator
+//  results := pushy.SendPersonalized(ctx, sdk, notifications, pushy.PersonalizedConfig{RequestsPerSecond: 10})
+func SendPersonalized(ctx context.Context, client IPushyClientWithOptions, recipients map[string]SendNotificationRequest, config PersonalizedConfig, opts ...CallOption) map[string]RecipientResult {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.MaxRecipients <= 0 || config.MaxRecipients > MaxRecipientsPerRequest {
+		config.MaxRecipients = MaxRecipientsPerRequest
+	}
+	results := make(map[string]RecipientResult, len(recipients))
+	groups, err := groupRecipients(recipients, config.MaxRecipients)
+	if err != nil {
+		for token := range recipients {
+			results[token] = RecipientResult{Err: err}
+		}
+		return results
+	}
+	key := NewCallOptions(opts...).IdempotencyKey
+
+	var mu sync.Mutex
+	record := func(to []string, result RecipientResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, token := range to {
+			results[token] = result
+		}
+	}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, config.Concurrency)
+	pace := newPacer(config.RequestsPerSecond)
+	for _, group := range groups {
+		// size is left to the client, which truncates notifications when it has a TruncateStrategy
+		if err := group.request.validateFields(); err != nil {
+			record(group.request.To, RecipientResult{Err: err})
+			continue
+		}
+		if err := pace.wait(ctx); err != nil {
+			record(group.request.To, RecipientResult{Err: err})
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			record(group.request.To, RecipientResult{Err: ctx.Err()})
+			continue
+		case slots <- struct{}{}:
+		}
+		groupOpts := opts
+		if key != "" {
+			groupOpts = append(append([]CallOption(nil), opts...), WithIdempotencyKey(group.idempotencyKey(key)))
+		}
+		wg.Add(1)
+		go func(request SendNotificationRequest, opts []CallOption) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			response, pushyErr, err := client.NotifyDeviceWithOptions(request, opts...)
+			record(request.To, RecipientResult{Response: response, PushyErr: pushyErr, Err: err})
+		}(group.request, groupOpts)
+	}
+	wg.Wait()
+	return results
+}
+
+// groupRecipients groups recipients by their notification, groups are sorted by content and recipients
+// so the same recipients always make the same requests
+func groupRecipients(recipients map[string]SendNotificationRequest, maxRecipients int) ([]personalizedGroup, error) {
+	byContent := map[string][]string{}
+	requests := map[string]SendNotificationRequest{}
+	for token, request := range recipients {
+		request.To = nil
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+		content := string(encoded)
+		byContent[content] = append(byContent[content], token)
+		requests[content] = request
+	}
+	contents := make([]string, 0, len(byContent))
+	for content := range byContent {
+		contents = append(contents, content)
+	}
+	sort.Strings(contents)
+	var groups []personalizedGroup
+	for _, content := range contents {
+		tokens := byContent[content]
+		sort.Strings(tokens)
+		for start := 0; start < len(tokens); start += maxRecipients {
+			end := start + maxRecipients
+			if end > len(tokens) {
+				end = len(tokens)
+			}
+			request := requests[content]
+			request.To = tokens[start:end]
+			groups = append(groups, personalizedGroup{content: content, request: request})
+		}
+	}
+	return groups, nil
+}
+
+// idempotencyKey derives a key of the group from key given for the whole send
+func (g personalizedGroup) idempotencyKey(key string) string {
+	sum := sha256.Sum256([]byte(g.content + "\n" + strings.Join(g.request.To, ",")))
+	return fmt.Sprintf("%s-%x", key, sum[:8])
+}
+
+// pacer spaces starts of requests evenly, a nil pacer doesn't wait. it's safe to use from multiple goroutines
+type pacer struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newPacer(perSecond float64) *pacer {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &pacer{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next request may start
+func (p *pacer) wait(ctx context.Context) error {
+	if p == nil {
+		return ctx.Err()
+	}
+	p.mu.Lock()
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(p.interval)
+	p.mu.Unlock()
+	return sleep(ctx, start.Sub(now))
+}
@@ -0,0 +1,165 @@
+package pushy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recipientsOf returns recipients of the notification server gave id, joined with commas
+func recipientsOf(server *pushytest.Server, id string) string {
+	for i, push := range server.Pushes() {
+		if fmt.Sprintf("PUSH%d", i+1) == id {
+			var request pushy.SendNotificationRequest
+			push.Decode(&request)
+			return strings.Join(request.To, ",")
+		}
+	}
+	return ""
+}
+
+func titled(title string) pushy.SendNotificationRequest {
+	return pushy.SendNotificationRequest{
+		To:              []string{"ignored"},
+		IOSNotification: pushy.IOSNotification{Title: title},
+	}
+}
+
+func TestSendPersonalized(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.Reject(func(r pushytest.Request) bool {
+		var request pushy.SendNotificationRequest
+		r.Decode(&request)
+		return request.IOSNotification.Title == "rejected"
+	})
+
+	invalid := titled("invalid")
+	invalid.AndroidOptions = pushy.AndroidOptions{Priority: "urgent"}
+	results := pushy.SendPersonalized(context.Background(), clientFor(server), map[string]pushy.SendNotificationRequest{
+		"A": titled("Hi Jenna"),
+		"B": titled("Hi all"),
+		"C": titled("Hi all"),
+		"D": invalid,
+		"E": titled("rejected"),
+	}, pushy.PersonalizedConfig{})
+
+	Assert.Len(results, 5)
+	Assert.Equal("A", recipientsOf(server, results["A"].Response.ID))
+	Assert.Equal("B,C", recipientsOf(server, results["B"].Response.ID), "recipients of the same notification share a request")
+	Assert.Equal(results["B"], results["C"])
+	Assert.IsType(pushy.ValidationError{}, results["D"].Err)
+	Assert.Nil(results["D"].Response)
+	Assert.Equal("rejected", results["E"].PushyErr.Error)
+	Assert.NotNil(results["E"].Err)
+	Assert.Len(server.Requests(), 3, "invalid notification isn't sent")
+}
+
+func TestSendPersonalized_MaxRecipients(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	recipients := map[string]pushy.SendNotificationRequest{}
+	for _, token := range []string{"A", "B", "C", "D", "E"} {
+		recipients[token] = titled("Hi")
+	}
+
+	results := pushy.SendPersonalized(context.Background(), clientFor(server), recipients, pushy.PersonalizedConfig{MaxRecipients: 2})
+	Assert.Equal("A,B", recipientsOf(server, results["A"].Response.ID))
+	Assert.Equal("C,D", recipientsOf(server, results["D"].Response.ID))
+	Assert.Equal("E", recipientsOf(server, results["E"].Response.ID))
+	Assert.Len(server.Requests(), 3)
+}
+
+func TestSendPersonalized_Limits(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetDelay(20 * time.Millisecond)
+	recipients := map[string]pushy.SendNotificationRequest{}
+	for _, token := range []string{"A", "B", "C", "D", "E", "F"} {
+		recipients[token] = titled("Hi " + token)
+	}
+
+	results := pushy.SendPersonalized(context.Background(), clientFor(server), recipients, pushy.PersonalizedConfig{Concurrency: 2})
+	Assert.Len(results, 6)
+	Assert.Len(server.Requests(), 6)
+	Assert.True(server.MaxActive() <= 2, "at most 2 requests at once, got %d", server.MaxActive())
+
+	server.SetDelay(0)
+	start := time.Now()
+	pushy.SendPersonalized(context.Background(), clientFor(server), recipients, pushy.PersonalizedConfig{RequestsPerSecond: 100})
+	Assert.True(time.Since(start) >= 50*time.Millisecond, "6 requests at 100/s take at least 50ms")
+}
+
+func TestSendPersonalized_Cancelled(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := pushy.SendPersonalized(ctx, clientFor(server), map[string]pushy.SendNotificationRequest{
+		"A": titled("Hi A"),
+		"B": titled("Hi B"),
+	}, pushy.PersonalizedConfig{})
+	Assert.Equal(context.Canceled, results["A"].Err)
+	Assert.Equal(context.Canceled, results["B"].Err)
+	Assert.Len(server.Requests(), 0)
+}
+
+func TestSendPersonalized_IdempotencyKey(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	client := pushy.NewDeduplicator(clientFor(server), pushy.DedupConfig{})
+	recipients := map[string]pushy.SendNotificationRequest{
+		"A": titled("Hi A"),
+		"B": titled("Hi B"),
+	}
+
+	first := pushy.SendPersonalized(context.Background(), client, recipients, pushy.PersonalizedConfig{}, pushy.WithIdempotencyKey("EVENT"))
+	Assert.Equal("A", recipientsOf(server, first["A"].Response.ID))
+	Assert.Equal("B", recipientsOf(server, first["B"].Response.ID), "every request gets its own key")
+	Assert.Len(server.Requests(), 2)
+	keys := []string{server.Requests()[0].Header.Get("Idempotency-Key"), server.Requests()[1].Header.Get("Idempotency-Key")}
+	Assert.NotEqual(keys[0], keys[1])
+	Assert.True(strings.HasPrefix(keys[0], "EVENT-"))
+
+	// redelivery of the same event isn't sent again
+	again := pushy.SendPersonalized(context.Background(), client, recipients, pushy.PersonalizedConfig{}, pushy.WithIdempotencyKey("EVENT"))
+	Assert.Equal(first, again)
+	Assert.Len(server.Requests(), 2)
+}
+
+func TestSendPersonalized_Truncate(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	sdk := clientFor(server)
+	large := titled("large")
+	large.IOSNotification.Body = strings.Repeat("a", pushy.MaxAPNsPayloadSize)
+	recipients := map[string]pushy.SendNotificationRequest{"A": large}
+
+	results := pushy.SendPersonalized(context.Background(), sdk, recipients, pushy.PersonalizedConfig{})
+	Assert.True(errors.Is(results["A"].Err, pushy.ErrPayloadTooLarge))
+	Assert.Len(server.Pushes(), 0)
+
+	// the client's strategy applies to personalized notifications as well
+	sdk.SetTruncateStrategy(pushy.TruncateEllipsis)
+	results = pushy.SendPersonalized(context.Background(), sdk, recipients, pushy.PersonalizedConfig{})
+	Assert.Nil(results["A"].Err)
+	Assert.Equal("PUSH1", results["A"].Response.ID)
+	var sent pushy.SendNotificationRequest
+	Assert.Nil(server.Pushes()[0].Decode(&sent))
+	Assert.True(strings.HasSuffix(sent.IOSNotification.Body, "…"))
+	Assert.True(pushy.SizeOf(sent).APNs <= pushy.MaxAPNsPayloadSize)
+}
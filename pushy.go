@@ -3,10 +3,10 @@
 package pushy
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -25,10 +25,12 @@ func GetDefaultAPIEndpoint() string {
 	return "https://api.pushy.me"
 }
 
-// GetDefaultHTTPClient returns a httpClient with configured timeout
+// GetDefaultHTTPClient returns a httpClient with configured timeout,
+// clients share a transport tuned with DefaultTransportConfig, use NewHTTPClient to tune it differently
 func GetDefaultHTTPClient(timeout time.Duration) IHTTPClient {
 	client := http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: defaultTransport{},
 	}
 	return IHTTPClient(&client)
 }
@@ -36,134 +38,182 @@ func GetDefaultHTTPClient(timeout time.Duration) IHTTPClient {
 // SetHTTPClient sets a http client, it's useful when you're using sandboxed env like appengine
 // this is required to do, pushy won't automatically use the default http client.
 func (p *Pushy) SetHTTPClient(client IHTTPClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.httpClient = client
 }
 
 // GetHTTPClient returns the client which is being used with pushy
 func (p *Pushy) GetHTTPClient() IHTTPClient {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.httpClient
 }
 
+// SetStreamingThreshold makes NotifyDevice stream requests with at least recipients tokens instead of
+// encoding them in memory first, which helps with campaigns to lots of devices. 0 disables streaming.
+// streamed requests are sent with chunked encoding
+func (p *Pushy) SetStreamingThreshold(recipients int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.streamingThreshold = recipients
+}
+
+// SetTruncateStrategy makes NotifyDevice shorten body of notifications which are too large for APNs with strategy
+// instead of rejecting them, nil rejects them
+//  sdk.SetTruncateStrategy(pushy.TruncateWords)
+func (p *Pushy) SetTruncateStrategy(strategy TruncateStrategy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.truncate = strategy
+}
+
+// SetMetaHeaders changes the response headers ResponseMeta reads request id and rate limit from
+//  sdk.SetMetaHeaders(pushy.MetaHeaders{RequestID: "X-Amzn-RequestId"})
+func (p *Pushy) SetMetaHeaders(headers MetaHeaders) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.metaHeaders = &headers
+}
+
+func (p *Pushy) getMetaHeaders() MetaHeaders {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.metaHeaders == nil {
+		return DefaultMetaHeaders()
+	}
+	return *p.metaHeaders
+}
+
+// fitPayload checks size of request, truncating it when there's a strategy
+func (p *Pushy) fitPayload(request SendNotificationRequest) (SendNotificationRequest, error) {
+	p.mu.RLock()
+	truncate := p.truncate
+	p.mu.RUnlock()
+	if truncate != nil {
+		return request.Truncate(truncate)
+	}
+	return request, SizeOf(request).check()
+}
+
+// SetAPIToken changes the api token used by requests which start after it returns,
+// use it instead of assigning APIToken once the client is shared between goroutines
+func (p *Pushy) SetAPIToken(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.APIToken = token
+}
+
+// SetAPIEndpoint changes the endpoint used by requests which start after it returns,
+// use it instead of assigning APIEndpoint once the client is shared between goroutines
+func (p *Pushy) SetAPIEndpoint(endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.APIEndpoint = endpoint
+}
+
 // DeviceInfo returns information about a particular device
-func (p Pushy) DeviceInfo(deviceID string) (*DeviceInfo, *Error, error) {
-	url := fmt.Sprintf("%s/devices/%s?api_key=%s", p.APIEndpoint, deviceID, p.APIToken)
-	var errResponse *Error
-	var info *DeviceInfo
-	err := get(p.httpClient, url, &info, &errResponse)
-	return info, errResponse, err
+func (p *Pushy) DeviceInfo(deviceID string) (*DeviceInfo, *Error, error) {
+	return p.DeviceInfoWithOptions(deviceID)
+}
+
+// DeviceInfoWithOptions is DeviceInfo with call options
+func (p *Pushy) DeviceInfoWithOptions(deviceID string, opts ...CallOption) (*DeviceInfo, *Error, error) {
+	return do[struct{}, DeviceInfo](context.Background(), p, http.MethodGet, "/devices/"+url.PathEscape(deviceID), nil, opts...)
 }
 
 // DevicePresence returns data about presence of a data
 func (p *Pushy) DevicePresence(deviceID ...string) (*DevicePresenceResponse, *Error, error) {
-	url := fmt.Sprintf("%s/devices/presence?api_key=%s", p.APIEndpoint, p.APIToken)
-	var devicePresenceResponse *DevicePresenceResponse
-	var pushyErr *Error
-	err := post(p.httpClient, url, DevicePresenceRequest{Tokens: deviceID}, &devicePresenceResponse, &pushyErr)
-	return devicePresenceResponse, pushyErr, err
+	return p.DevicePresenceWithOptions(deviceID)
+}
+
+// DevicePresenceWithOptions is DevicePresence with call options
+func (p *Pushy) DevicePresenceWithOptions(deviceIDs []string, opts ...CallOption) (*DevicePresenceResponse, *Error, error) {
+	request := &DevicePresenceRequest{Tokens: deviceIDs}
+	return do[DevicePresenceRequest, DevicePresenceResponse](context.Background(), p, http.MethodPost, "/devices/presence", request, opts...)
 }
 
 // NotificationStatus returns status of a particular notification
 func (p *Pushy) NotificationStatus(pushID string) (*NotificationStatus, *Error, error) {
-	url := fmt.Sprintf("%s/pushes/%s?api_key=%s", p.APIEndpoint, pushID, p.APIToken)
-	var errResponse *Error
-	var status *NotificationStatus
-	err := get(p.httpClient, url, &status, &errResponse)
-	return status, errResponse, err
+	return p.NotificationStatusWithOptions(pushID)
+}
+
+// NotificationStatusWithOptions is NotificationStatus with call options
+func (p *Pushy) NotificationStatusWithOptions(pushID string, opts ...CallOption) (*NotificationStatus, *Error, error) {
+	return do[struct{}, NotificationStatus](context.Background(), p, http.MethodGet, "/pushes/"+url.PathEscape(pushID), nil, opts...)
 }
 
 // DeleteNotification deletes a created notification
 func (p *Pushy) DeleteNotification(pushID string) (*SimpleSuccess, *Error, error) {
-	url := fmt.Sprintf("%s/pushes/%s?api_key=%s", p.APIEndpoint, pushID, p.APIToken)
-	var success *SimpleSuccess
-	var pushyErr *Error
-	err := del(p.httpClient, url, &success, &pushyErr)
-	return success, pushyErr, err
+	return p.DeleteNotificationWithOptions(pushID)
+}
+
+// DeleteNotificationWithOptions is DeleteNotification with call options
+func (p *Pushy) DeleteNotificationWithOptions(pushID string, opts ...CallOption) (*SimpleSuccess, *Error, error) {
+	return do[struct{}, SimpleSuccess](context.Background(), p, http.MethodDelete, "/pushes/"+url.PathEscape(pushID), nil, opts...)
 }
 
 // SubscribeToTopic subscribes a particular device to topics (when you want to do from backend)
 func (p *Pushy) SubscribeToTopic(deviceID string, topics ...string) (*SimpleSuccess, *Error, error) {
-	url := fmt.Sprintf("%s/devices/subscribe?api_key=%s", p.APIEndpoint, p.APIToken)
-	request := DeviceSubscriptionRequest{
+	return p.SubscribeToTopicWithOptions(deviceID, topics)
+}
+
+// SubscribeToTopicWithOptions is SubscribeToTopic with call options
+func (p *Pushy) SubscribeToTopicWithOptions(deviceID string, topics []string, opts ...CallOption) (*SimpleSuccess, *Error, error) {
+	request := &DeviceSubscriptionRequest{
 		Token:  deviceID,
 		Topics: topics,
 	}
-	var success *SimpleSuccess
-	var pushyErr *Error
-	err := post(p.httpClient, url, request, &success, &pushyErr)
-	return success, pushyErr, err
+	return do[DeviceSubscriptionRequest, SimpleSuccess](context.Background(), p, http.MethodPost, "/devices/subscribe", request, opts...)
 }
 
 // UnsubscribeFromTopic un subscribes a particular device from topics (when you want to do from backend)
 func (p *Pushy) UnsubscribeFromTopic(token string, topics ...string) (*SimpleSuccess, *Error, error) {
-	url := fmt.Sprintf("%s/devices/unsubscribe?api_key=%s", p.APIEndpoint, p.APIToken)
-	request := DeviceSubscriptionRequest{
+	return p.UnsubscribeFromTopicWithOptions(token, topics)
+}
+
+// UnsubscribeFromTopicWithOptions is UnsubscribeFromTopic with call options
+func (p *Pushy) UnsubscribeFromTopicWithOptions(token string, topics []string, opts ...CallOption) (*SimpleSuccess, *Error, error) {
+	request := &DeviceSubscriptionRequest{
 		Token:  token,
 		Topics: topics,
 	}
-	var success *SimpleSuccess
-	var pushyErr *Error
-	err := post(p.httpClient, url, request, &success, &pushyErr)
-	return success, pushyErr, err
+	return do[DeviceSubscriptionRequest, SimpleSuccess](context.Background(), p, http.MethodPost, "/devices/unsubscribe", request, opts...)
+}
+
+// Topics returns all topics with at least one subscriber
+func (p *Pushy) Topics(opts ...CallOption) (*TopicsResponse, *Error, error) {
+	return do[struct{}, TopicsResponse](context.Background(), p, http.MethodGet, "/topics", nil, opts...)
 }
 
-// NotifyDevice sends notification data to devices
+// NotifyDevice sends notification data to devices, requests which are too large to be delivered
+// are rejected with PayloadTooLargeError without being sent, see SetTruncateStrategy
 func (p *Pushy) NotifyDevice(request SendNotificationRequest) (*NotificationResponse, *Error, error) {
-	url := fmt.Sprintf("%s/push?api_key=%s", p.APIEndpoint, p.APIToken)
-	var success *NotificationResponse
-	var pushyErr *Error
-	err := post(p.httpClient, url, request, &success, &pushyErr)
-	return success, pushyErr, err
+	return p.NotifyDeviceWithOptions(request)
 }
 
-func get(client IHTTPClient, url string, positiveResponse interface{}, errResponse interface{}) error {
-	response, err := client.Get(url)
+// NotifyDeviceWithOptions is NotifyDevice with call options
+func (p *Pushy) NotifyDeviceWithOptions(request SendNotificationRequest, opts ...CallOption) (*NotificationResponse, *Error, error) {
+	request, err := p.fitPayload(request)
 	if err != nil {
-		positiveResponse = nil
-		errResponse = nil
-		return err
+		return nil, nil, err
 	}
-	defer response.Body.Close()
-	body := response.Body
-	if response.StatusCode >= 400 {
-		positiveResponse = nil
-		json.NewDecoder(body).Decode(&errResponse)
-		return fmt.Errorf("%d %s", response.StatusCode, response.Status)
-	}
-	// decode positiveResponse
-	errResponse = nil
-	return json.NewDecoder(body).Decode(positiveResponse)
+	return do[SendNotificationRequest, NotificationResponse](context.Background(), p, http.MethodPost, "/push", &request, opts...)
 }
 
-func post(client IHTTPClient, url string, body interface{}, posRes interface{}, errRes interface{}) error {
-	buffer := new(bytes.Buffer)
-	json.NewEncoder(buffer).Encode(body)
-	response, err := client.Post(url, "application/json", buffer)
-	if err != nil {
-		return err
-	}
-	defer response.Body.Close()
-	b := response.Body
-	if response.StatusCode >= 400 {
-		json.NewDecoder(b).Decode(&errRes)
-		return fmt.Errorf("%d %s", response.StatusCode, response.Status)
-	}
-	return json.NewDecoder(b).Decode(posRes)
+// StatusError is the error returned along with *Error when pushy responds with an error status,
+// use errors.As to tell apart requests pushy rejected from those which never got a response
+//  _, _, err := sdk.DeviceInfo(deviceID)
+//  var statusErr pushy.StatusError
+//  if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+//  	// device doesn't exist
+//  }
+type StatusError struct {
+	// Code is the http status code, Status the status line such as "404 Not Found"
+	Code   int
+	Status string
 }
 
-func del(client IHTTPClient, url string, posRes interface{}, errRes interface{}) error {
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return err // I don't think there's anything which can result in this error.
-	}
-	response, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer response.Body.Close()
-	b := response.Body
-	if response.StatusCode >= 400 {
-		json.NewDecoder(b).Decode(&errRes)
-		return fmt.Errorf("%d %s", response.StatusCode, response.Status)
-	}
-	return json.NewDecoder(b).Decode(posRes)
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Status)
 }
@@ -1,6 +1,7 @@
 package pushy_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -16,7 +17,6 @@ func ExampleGetDefaultAPIEndpoint() {
 }
 
 func setupNotifyStuff() func() {
-	httpmock.Activate()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/push?api_key=%s", apiToken)
 	expectedResponse := `{"success":true, "id":"some_id"}`
@@ -24,7 +24,6 @@ func setupNotifyStuff() func() {
 	return httpmock.DeactivateAndReset
 }
 func setupNotifyDeletionStuff() func() {
-	httpmock.Activate()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/pushes/some_id?api_key=%s", apiToken)
 	expectedResponse := `{"success":true, "id":"some_id"}`
@@ -32,7 +31,6 @@ func setupNotifyDeletionStuff() func() {
 	return httpmock.DeactivateAndReset
 }
 func setupDeviceInfoStuff() func() {
-	httpmock.Activate()
 	expectedResponse := `
 {
   "device": {
@@ -67,7 +65,6 @@ func setupDeviceInfoStuff() func() {
 	return httpmock.DeactivateAndReset
 }
 func setupDevicePresenceStuff() func() {
-	httpmock.Activate()
 	expectedResponse := `{
   "presence": [
     {
@@ -82,7 +79,6 @@ func setupDevicePresenceStuff() func() {
 	return httpmock.DeactivateAndReset
 }
 func setupNotificationStatusStuff() func() {
-	httpmock.Activate()
 	expectedResponse := `
 {
   "push": {
@@ -102,14 +98,12 @@ func setupNotificationStatusStuff() func() {
 	return httpmock.DeactivateAndReset
 }
 func setupSubscribeToTopicStuff() func() {
-	httpmock.Activate()
 	expectedResponse := `{"success": true}`
 	endpoint := "https://api.pushy.me/devices/subscribe?api_key=API_TOKEN"
 	httpmock.RegisterResponder("POST", endpoint, httpmock.NewStringResponder(http.StatusOK, expectedResponse))
 	return httpmock.DeactivateAndReset
 }
 func setupUnSubscribeFromTopicStuff() func() {
-	httpmock.Activate()
 	expectedResponse := `{"success": true}`
 	endpoint := "https://api.pushy.me/devices/unsubscribe?api_key=API_TOKEN"
 	httpmock.RegisterResponder("POST", endpoint, httpmock.NewStringResponder(http.StatusOK, expectedResponse))
@@ -120,7 +114,7 @@ func ExamplePushy_NotifyDevice() {
 	cleaner := setupNotifyStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 	status, _, _ := sdk.NotifyDevice(pushy.SendNotificationRequest{})
 	fmt.Println(status.Success)
 	fmt.Println(status.ID)
@@ -133,7 +127,7 @@ func ExamplePushy_DeleteNotification() {
 	cleaner := setupNotifyDeletionStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 	status, _, _ := sdk.DeleteNotification("some_id")
 	fmt.Print(status.Success)
 	// Output:
@@ -144,7 +138,7 @@ func ExamplePushy_DeviceInfo() {
 	cleaner := setupDeviceInfoStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 
 	res, _, _ := sdk.DeviceInfo("DEVICE_ID")
 	fmt.Println(res.Presence.Online)
@@ -158,11 +152,11 @@ func ExamplePushy_DevicePresence() {
 	cleaner := setupDevicePresenceStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 	presence, _, _ := sdk.DevicePresence("DEVICE_ID")
 	fmt.Println(presence.Presence[0].ID)
 	fmt.Println(presence.Presence[0].Online)
-	fmt.Println(presence.Presence[0].LastActive)
+	fmt.Println(presence.Presence[0].LastActive.Unix())
 	// Output:
 	// a6f36efb913f1def30c6
 	// false
@@ -173,10 +167,10 @@ func ExamplePushy_NotificationStatus() {
 	cleaner := setupNotificationStatusStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 	status, _, _ := sdk.NotificationStatus("PUSH_ID")
-	fmt.Println(status.Push.Expiration)
-	fmt.Println(status.Push.Date)
+	fmt.Println(status.Push.Expiration.Unix())
+	fmt.Println(status.Push.Date.Unix())
 	fmt.Println(status.Push.Payload) // todo isn't working for some reason.
 	// Output:
 	// 1466595935
@@ -188,7 +182,7 @@ func ExamplePushy_SubscribeToTopic() {
 	cleaner := setupSubscribeToTopicStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 	subscription, _, _ := sdk.SubscribeToTopic("DEVICE_ID", "TOPIC")
 	fmt.Println(subscription.Success)
 	// Output:
@@ -199,9 +193,61 @@ func ExamplePushy_UnsubscribeFromTopic() {
 	cleaner := setupUnSubscribeFromTopicStuff()
 	defer cleaner()
 	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 	subscription, _, _ := sdk.UnsubscribeFromTopic("DEVICE_ID", "TOPIC")
 	fmt.Println(subscription.Success)
 	// Output:
 	// true
 }
+
+func ExampleNotificationBuilder() {
+	cleaner := setupNotifyStuff()
+	defer cleaner()
+	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
+	// android app reads data, iOS shows notification and browsers show web notification
+	request, err := pushy.NewNotificationBuilder("ANDROID_DEVICE", "IOS_DEVICE", "BROWSER").
+		Data(`{"sale_id":42}`).
+		Android(pushy.AndroidOptions{Priority: pushy.AndroidPriorityHigh, CollapseKey: "sale"}).
+		IOS(pushy.IOSNotification{Title: "Flash sale", Body: "Everything is 50% off"}).
+		Web(pushy.WebNotification{
+			Title:   "Flash sale",
+			Message: "Everything is 50% off",
+			URL:     "https://example.com/sale",
+			Image:   "https://example.com/icon.png",
+		}).
+		Build()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	status, _, _ := sdk.NotifyDevice(request)
+	fmt.Println(status.Success)
+	// Output:
+	// true
+}
+
+func ExampleNotificationBuilder_validation() {
+	_, err := pushy.NewNotificationBuilder("BROWSER").
+		Web(pushy.WebNotification{Title: "Flash sale", URL: "/sale"}).
+		Build()
+	fmt.Println(err)
+	// Output:
+	// data.url: "/sale" is not an absolute http(s) url
+}
+
+func ExampleStatusError() {
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://api.pushy.me/devices/UNKNOWN?api_key=API_TOKEN",
+		httpmock.NewStringResponder(http.StatusNotFound, `{"error":"device not found"}`))
+	sdk := pushy.Create("API_TOKEN", pushy.GetDefaultAPIEndpoint())
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
+
+	_, pushyErr, err := sdk.DeviceInfo("UNKNOWN")
+	var statusErr pushy.StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+		fmt.Println(pushyErr.Error)
+	}
+	// Output:
+	// device not found
+}
@@ -4,14 +4,34 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/jarcoal/httpmock.v1"
 )
 
+var (
+	_ pushy.IPushyClient            = (*pushy.Pushy)(nil)
+	_ pushy.IPushyClientWithOptions = (*pushy.Pushy)(nil)
+	_ pushy.ITopicsClient           = (*pushy.Pushy)(nil)
+)
+
+// clientFor returns a client talking to server
+func clientFor(server *pushytest.Server) *pushy.Pushy {
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	return sdk
+}
+
+// mockedHTTPClient returns a client which sends requests to responders registered with httpmock
+func mockedHTTPClient(timeout time.Duration) pushy.IHTTPClient {
+	return &http.Client{Timeout: timeout, Transport: httpmock.DefaultTransport}
+}
+
 type endpoint struct {
 	method string
 	url    string
@@ -85,7 +105,6 @@ func TestPushy_GetHTTPClient2(t *testing.T) {
 // endregion
 
 func TestEverythingHandlesBadRequest(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	deviceToken := "DEVICE"
@@ -96,7 +115,7 @@ func TestEverythingHandlesBadRequest(t *testing.T) {
 		httpmock.RegisterResponder(endpoint.method, url, httpmock.NewStringResponder(http.StatusBadRequest, body))
 	}
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 
 	deviceInfo, pushyErr, err := sdk.DeviceInfo(deviceToken)
 	Assert := assert.New(t)
@@ -129,6 +148,11 @@ func TestEverythingHandlesBadRequest(t *testing.T) {
 	Assert.NotNil(pushyErr)
 	Assert.Nil(unSubscription)
 
+	topics, pushyErr, err := sdk.Topics()
+	Assert.Contains(err.Error(), "400")
+	Assert.NotNil(pushyErr)
+	Assert.Nil(topics)
+
 	notifyDevice, pushyErr, err := sdk.NotifyDevice(pushy.SendNotificationRequest{})
 	Assert.Contains(err.Error(), "400")
 	Assert.NotNil(pushyErr)
@@ -136,7 +160,6 @@ func TestEverythingHandlesBadRequest(t *testing.T) {
 }
 
 func TestEverythingHandlesNetworkError(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	deviceToken := "DEVICE"
@@ -147,7 +170,7 @@ func TestEverythingHandlesNetworkError(t *testing.T) {
 	}
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
 
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(10 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(10 * time.Millisecond))
 
 	deviceInfo, pushyErr, err := sdk.DeviceInfo(deviceToken)
 	Assert := assert.New(t)
@@ -170,10 +193,12 @@ func TestEverythingHandlesNetworkError(t *testing.T) {
 	Assert.Nil(pushyErr)
 	Assert.Nil(deleteNotification)
 
-	invalidUrlParameter, pushyErr, err := sdk.DeleteNotification("TOKE%%%%%N")
-	Assert.Contains(err.Error(), "invalid URL escape")
+	// ids are escaped, so they can't make an invalid url
+	httpmock.RegisterResponder("DELETE", "https://api.pushy.me/pushes/TOKE%25%25%25%25%25N?api_key=API_TOKEN", httpmock.NewErrorResponder(errors.New("ERR CONN RESET")))
+	escapedParameter, pushyErr, err := sdk.DeleteNotification("TOKE%%%%%N")
+	Assert.Contains(err.Error(), "ERR CONN RESET")
 	Assert.Nil(pushyErr)
-	Assert.Nil(invalidUrlParameter)
+	Assert.Nil(escapedParameter)
 
 	subscription, pushyErr, err := sdk.SubscribeToTopic("S", "topic")
 	Assert.Contains(err.Error(), "ERR CONN RESET")
@@ -185,6 +210,11 @@ func TestEverythingHandlesNetworkError(t *testing.T) {
 	Assert.Nil(pushyErr)
 	Assert.Nil(unSubscription)
 
+	topics, pushyErr, err := sdk.Topics()
+	Assert.Contains(err.Error(), "ERR CONN RESET")
+	Assert.Nil(pushyErr)
+	Assert.Nil(topics)
+
 	notifyDevice, pushyErr, err := sdk.NotifyDevice(pushy.SendNotificationRequest{})
 	Assert.Contains(err.Error(), "ERR CONN RESET")
 	Assert.Nil(pushyErr)
@@ -193,7 +223,6 @@ func TestEverythingHandlesNetworkError(t *testing.T) {
 
 // region API communication
 func TestPushy_DeviceInfo(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	deviceToken := "DEVICE"
@@ -229,12 +258,12 @@ func TestPushy_DeviceInfo(t *testing.T) {
 	httpmock.RegisterResponder("GET", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	Assert := assert.New(t)
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 	info, pushyError, err := sdk.DeviceInfo(deviceToken)
 	Assert.Nil(pushyError)
 	Assert.Nil(err)
-	Assert.Equal("android", info.Device.Platform)
-	Assert.Equal(1000, info.Device.Date)
+	Assert.Equal(pushy.PlatformAndroid, info.Device.Platform)
+	Assert.Equal(int64(1000), info.Device.Date.Unix())
 	Assert.Equal("media", info.Subscriptions[0])
 	Assert.Equal(true, info.Presence.Online)
 	Assert.Equal(215, info.Presence.LastActive.SecondsAgo)
@@ -242,7 +271,6 @@ func TestPushy_DeviceInfo(t *testing.T) {
 }
 
 func TestPushy_DevicePresence(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	deviceToken := "DEVICE"
@@ -261,16 +289,15 @@ func TestPushy_DevicePresence(t *testing.T) {
 	httpmock.RegisterResponder("POST", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	Assert := assert.New(t)
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 
 	info, _, _ := sdk.DevicePresence(deviceToken)
 	Assert.Equal(false, info.Presence[0].Online)
 	Assert.Equal("a6f36efb913f1def30c6", info.Presence[0].ID)
-	Assert.Equal(1429406442, info.Presence[0].LastActive)
+	Assert.Equal(int64(1429406442), info.Presence[0].LastActive.Unix())
 }
 
 func TestPushy_NotificationStatus(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/pushes/PUSH_ID?api_key=%s", apiToken)
@@ -291,32 +318,51 @@ func TestPushy_NotificationStatus(t *testing.T) {
 	httpmock.RegisterResponder("GET", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	Assert := assert.New(t)
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 
 	status, _, _ := sdk.NotificationStatus("PUSH_ID")
-	Assert.Equal(100, status.Push.Date)
+	Assert.Equal(int64(100), status.Push.Date.Unix())
 	payloadMap, _ := status.Push.Payload.(map[string]interface{})
 	Assert.Contains(payloadMap["message"], "Hello World!")
-	Assert.Equal(105, status.Push.Expiration)
+	Assert.Equal(int64(105), status.Push.Expiration.Unix())
 	Assert.Equal("device_id", status.Push.PendingDevices[0])
 }
 
 func TestPushy_DeleteNotification(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/pushes/PUSH_ID?api_key=%s", apiToken)
 	expectedResponse := `{"success":true}`
 	httpmock.RegisterResponder("DELETE", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 
 	status, _, _ := sdk.DeleteNotification("PUSH_ID")
 	assert.Equal(t, true, status.Success)
 }
 
+func TestPushy_EscapesIDs(t *testing.T) {
+	Assert := assert.New(t)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath()+" "+r.URL.Query().Get("api_key"))
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+
+	sdk.DeviceInfo("a/b")
+	sdk.NotificationStatus("../topics")
+	sdk.DeleteNotification("id?api_key=OTHER#x")
+	Assert.Equal([]string{
+		"/devices/a%2Fb SECRET",
+		"/pushes/..%2Ftopics SECRET",
+		"/pushes/id%3Fapi_key=OTHER%23x SECRET",
+	}, paths)
+}
+
 func TestPushy_SubscribeToTopic(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/devices/subscribe?api_key=%s", apiToken)
@@ -324,14 +370,13 @@ func TestPushy_SubscribeToTopic(t *testing.T) {
 	httpmock.RegisterResponder("POST", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	Assert := assert.New(t)
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 
 	status, _, _ := sdk.SubscribeToTopic("TOKEN", "topic")
 	Assert.Equal(true, status.Success)
 }
 
 func TestPushy_UnsubscribeFromTopic(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/devices/unsubscribe?api_key=%s", apiToken)
@@ -339,14 +384,29 @@ func TestPushy_UnsubscribeFromTopic(t *testing.T) {
 	httpmock.RegisterResponder("POST", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	Assert := assert.New(t)
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 
 	status, _, _ := sdk.UnsubscribeFromTopic("TOKEN", "topic")
 	Assert.Equal(true, status.Success)
 }
 
+func TestPushy_Topics(t *testing.T) {
+	defer httpmock.DeactivateAndReset()
+	apiToken := "API_TOKEN"
+	endpoint := fmt.Sprintf("https://api.pushy.me/topics?api_key=%s", apiToken)
+	expectedResponse := `{"topics":[{"name":"news","subscribers":2},{"name":"media","subscribers":1}]}`
+	httpmock.RegisterResponder("GET", endpoint, httpmock.NewStringResponder(200, expectedResponse))
+	Assert := assert.New(t)
+	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
+
+	topics, _, _ := sdk.Topics()
+	Assert.Len(topics.Topics, 2)
+	Assert.Equal("news", topics.Topics[0].Name)
+	Assert.Equal(2, topics.Topics[0].Subscribers)
+}
+
 func TestPushy_NotifyDevice(t *testing.T) {
-	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	apiToken := "API_TOKEN"
 	endpoint := fmt.Sprintf("https://api.pushy.me/push?api_key=%s", apiToken)
@@ -354,7 +414,7 @@ func TestPushy_NotifyDevice(t *testing.T) {
 	httpmock.RegisterResponder("POST", endpoint, httpmock.NewStringResponder(200, expectedResponse))
 	Assert := assert.New(t)
 	sdk := pushy.Create(apiToken, pushy.GetDefaultAPIEndpoint())
-	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(100 * time.Millisecond))
+	sdk.SetHTTPClient(mockedHTTPClient(100 * time.Millisecond))
 
 	status, _, _ := sdk.NotifyDevice(pushy.SendNotificationRequest{})
 	Assert.Equal(true, status.Success)
@@ -389,6 +449,10 @@ func getEndpointsDefinitions() []endpoint {
 			method: "POST",
 			url:    "/devices/unsubscribe?api_key=API_TOKEN",
 		},
+		{
+			method: "GET",
+			url:    "/topics?api_key=API_TOKEN",
+		},
 		{
 			method: "POST",
 			url:    "/push?api_key=API_TOKEN",
@@ -0,0 +1,248 @@
+package pushygrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Client implements pushy.IPushyClientWithOptions on top of a pushy gRPC server
+type Client struct {
+	client     pushypb.PushyServiceClient
+	timeout    time.Duration
+	httpClient pushy.IHTTPClient
+}
+
+// NewClient creates a client using conn, every call is limited to timeout (0 means no limit)
+func NewClient(conn grpc.ClientConnInterface, timeout time.Duration) *Client {
+	return &Client{
+		client:  pushypb.NewPushyServiceClient(conn),
+		timeout: timeout,
+	}
+}
+
+// SetHTTPClient is only there to satisfy pushy.IPushyClient, requests are made over gRPC connection
+func (c *Client) SetHTTPClient(client pushy.IHTTPClient) {
+	c.httpClient = client
+}
+
+// GetHTTPClient returns client set with SetHTTPClient, it isn't used by Client
+func (c *Client) GetHTTPClient() pushy.IHTTPClient {
+	return c.httpClient
+}
+
+// context starts a call, done must be called once it's over to fill what was asked for with opts.
+// pushy.WithTimeout replaces timeout of the client and the idempotency key is relayed to the server,
+// retries and headers aren't, configure them on the client of the server instead.
+// a call over gRPC is a single attempt and http metadata of pushy isn't relayed, so only
+// Latency and Attempts of pushy.ResponseMeta are filled
+func (c *Client) context(opts ...pushy.CallOption) (ctx context.Context, done func()) {
+	options := pushy.NewCallOptions(opts...)
+	start := time.Now()
+	timeout := c.timeout
+	if options.Timeout > 0 {
+		timeout = options.Timeout
+	}
+	var cancel context.CancelFunc
+	if timeout == 0 {
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	if options.IdempotencyKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, idempotencyKey, options.IdempotencyKey)
+	}
+	return ctx, func() {
+		cancel()
+		if options.Meta != nil {
+			*options.Meta = pushy.ResponseMeta{Latency: time.Since(start), Attempts: 1}
+		}
+	}
+}
+
+// fromStatus restores pushy.Error from status details, so callers can handle it same way as with pushy.Pushy
+func fromStatus(err error) (*pushy.Error, error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, err
+	}
+	for _, detail := range st.Details() {
+		if pushyErr, ok := detail.(*pushypb.PushyError); ok {
+			return &pushy.Error{Error: pushyErr.GetError()}, errors.New(st.Message())
+		}
+	}
+	return nil, err
+}
+
+// DeviceInfo returns information about a particular device
+func (c *Client) DeviceInfo(deviceID string) (*pushy.DeviceInfo, *pushy.Error, error) {
+	return c.DeviceInfoWithOptions(deviceID)
+}
+
+// DeviceInfoWithOptions is DeviceInfo with call options
+func (c *Client) DeviceInfoWithOptions(deviceID string, opts ...pushy.CallOption) (*pushy.DeviceInfo, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.DeviceInfo(ctx, &pushypb.DeviceInfoRequest{DeviceId: deviceID})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return fromDeviceInfo(res), nil, nil
+}
+
+// DevicePresence returns presence of devices
+func (c *Client) DevicePresence(deviceID ...string) (*pushy.DevicePresenceResponse, *pushy.Error, error) {
+	return c.DevicePresenceWithOptions(deviceID)
+}
+
+// DevicePresenceWithOptions is DevicePresence with call options
+func (c *Client) DevicePresenceWithOptions(deviceIDs []string, opts ...pushy.CallOption) (*pushy.DevicePresenceResponse, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.DevicePresence(ctx, &pushypb.DevicePresenceRequest{DeviceIds: deviceIDs})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return fromDevicePresence(res), nil, nil
+}
+
+// NotificationStatus returns status of a particular notification
+func (c *Client) NotificationStatus(pushID string) (*pushy.NotificationStatus, *pushy.Error, error) {
+	return c.NotificationStatusWithOptions(pushID)
+}
+
+// NotificationStatusWithOptions is NotificationStatus with call options
+func (c *Client) NotificationStatusWithOptions(pushID string, opts ...pushy.CallOption) (*pushy.NotificationStatus, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.NotificationStatus(ctx, &pushypb.NotificationStatusRequest{PushId: pushID})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return fromNotificationStatus(res), nil, nil
+}
+
+// DeleteNotification deletes a created notification
+func (c *Client) DeleteNotification(pushID string) (*pushy.SimpleSuccess, *pushy.Error, error) {
+	return c.DeleteNotificationWithOptions(pushID)
+}
+
+// DeleteNotificationWithOptions is DeleteNotification with call options
+func (c *Client) DeleteNotificationWithOptions(pushID string, opts ...pushy.CallOption) (*pushy.SimpleSuccess, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.DeleteNotification(ctx, &pushypb.DeleteNotificationRequest{PushId: pushID})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return &pushy.SimpleSuccess{Success: res.GetSuccess()}, nil, nil
+}
+
+// SubscribeToTopic subscribes a device to topics
+func (c *Client) SubscribeToTopic(deviceID string, topics ...string) (*pushy.SimpleSuccess, *pushy.Error, error) {
+	return c.SubscribeToTopicWithOptions(deviceID, topics)
+}
+
+// SubscribeToTopicWithOptions is SubscribeToTopic with call options
+func (c *Client) SubscribeToTopicWithOptions(deviceID string, topics []string, opts ...pushy.CallOption) (*pushy.SimpleSuccess, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.SubscribeToTopic(ctx, &pushypb.TopicSubscriptionRequest{DeviceId: deviceID, Topics: topics})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return &pushy.SimpleSuccess{Success: res.GetSuccess()}, nil, nil
+}
+
+// UnsubscribeFromTopic unsubscribes a device from topics
+func (c *Client) UnsubscribeFromTopic(token string, topics ...string) (*pushy.SimpleSuccess, *pushy.Error, error) {
+	return c.UnsubscribeFromTopicWithOptions(token, topics)
+}
+
+// UnsubscribeFromTopicWithOptions is UnsubscribeFromTopic with call options
+func (c *Client) UnsubscribeFromTopicWithOptions(token string, topics []string, opts ...pushy.CallOption) (*pushy.SimpleSuccess, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.UnsubscribeFromTopic(ctx, &pushypb.TopicSubscriptionRequest{DeviceId: token, Topics: topics})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return &pushy.SimpleSuccess{Success: res.GetSuccess()}, nil, nil
+}
+
+// Topics returns all topics with at least one subscriber
+func (c *Client) Topics(opts ...pushy.CallOption) (*pushy.TopicsResponse, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.Topics(ctx, &pushypb.TopicsRequest{})
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return fromTopics(res), nil, nil
+}
+
+// NotifyDevice sends notification data to devices
+func (c *Client) NotifyDevice(request pushy.SendNotificationRequest) (*pushy.NotificationResponse, *pushy.Error, error) {
+	return c.NotifyDeviceWithOptions(request)
+}
+
+// NotifyDeviceWithOptions is NotifyDevice with call options
+func (c *Client) NotifyDeviceWithOptions(request pushy.SendNotificationRequest, opts ...pushy.CallOption) (*pushy.NotificationResponse, *pushy.Error, error) {
+	ctx, done := c.context(opts...)
+	defer done()
+	res, err := c.client.NotifyDevice(ctx, toSendRequest(request))
+	if err != nil {
+		pushyErr, err := fromStatus(err)
+		return nil, pushyErr, err
+	}
+	return fromNotificationResponse(res), nil, nil
+}
+
+// BatchResult is the outcome of a single request sent with NotifyDevices
+type BatchResult struct {
+	Response *pushy.NotificationResponse
+	PushyErr *pushy.Error
+	Err      error
+}
+
+// NotifyDevices sends requests in a single call, results are in the same order as requests
+func (c *Client) NotifyDevices(requests []pushy.SendNotificationRequest) ([]BatchResult, error) {
+	ctx, done := c.context()
+	defer done()
+	batch := &pushypb.BatchSendRequest{}
+	for _, request := range requests {
+		batch.Requests = append(batch.Requests, toSendRequest(request))
+	}
+	res, err := c.client.NotifyDevices(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, 0, len(res.GetResults()))
+	for _, r := range res.GetResults() {
+		var result BatchResult
+		if r.GetResponse() != nil {
+			result.Response = fromNotificationResponse(r.GetResponse())
+		}
+		if r.GetPushyError() != nil {
+			result.PushyErr = &pushy.Error{Error: r.GetPushyError().GetError()}
+		}
+		if r.GetError() != "" {
+			result.Err = errors.New(r.GetError())
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,240 @@
+package pushygrpc
+
+import (
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// conversions between pushy types and their protobuf counterparts, zero times are sent as nil timestamps
+
+func toTimestamp(t pushy.UnixTime) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
+
+func fromTimestamp(t *timestamppb.Timestamp) pushy.UnixTime {
+	if t == nil {
+		return pushy.UnixTime{}
+	}
+	return pushy.UnixTime{Time: t.AsTime().Local()}
+}
+
+func toValue(payload interface{}) (*structpb.Value, error) {
+	if payload == nil {
+		return nil, nil
+	}
+	return structpb.NewValue(payload)
+}
+
+func fromValue(value *structpb.Value) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.AsInterface()
+}
+
+func toDeviceInfo(info *pushy.DeviceInfo) (*pushypb.DeviceInfoResponse, error) {
+	res := &pushypb.DeviceInfoResponse{
+		Device: &pushypb.Device{
+			Date:     toTimestamp(info.Device.Date),
+			Platform: string(info.Device.Platform),
+		},
+		Subscriptions: info.Subscriptions,
+		Presence: &pushypb.DevicePresenceInfo{
+			Online: info.Presence.Online,
+			LastActive: &pushypb.LastActive{
+				Date:       toTimestamp(info.Presence.LastActive.Date),
+				SecondsAgo: int64(info.Presence.LastActive.SecondsAgo),
+			},
+		},
+	}
+	for _, notification := range info.PendingNotifications {
+		payload, err := toValue(notification.Payload)
+		if err != nil {
+			return nil, err
+		}
+		res.PendingNotifications = append(res.PendingNotifications, &pushypb.Notification{
+			Id:         notification.ID,
+			Date:       toTimestamp(notification.Date),
+			Payload:    payload,
+			Expiration: toTimestamp(notification.Expiration),
+		})
+	}
+	return res, nil
+}
+
+func fromDeviceInfo(res *pushypb.DeviceInfoResponse) *pushy.DeviceInfo {
+	info := &pushy.DeviceInfo{
+		Device: pushy.Device{
+			Date:     fromTimestamp(res.GetDevice().GetDate()),
+			Platform: pushy.Platform(res.GetDevice().GetPlatform()),
+		},
+		Subscriptions: res.GetSubscriptions(),
+		Presence: pushy.DevicePresenceInfo{
+			Online: res.GetPresence().GetOnline(),
+			LastActive: pushy.LastActive{
+				Date:       fromTimestamp(res.GetPresence().GetLastActive().GetDate()),
+				SecondsAgo: int(res.GetPresence().GetLastActive().GetSecondsAgo()),
+			},
+		},
+	}
+	for _, notification := range res.GetPendingNotifications() {
+		info.PendingNotifications = append(info.PendingNotifications, pushy.Notification{
+			ID:         notification.GetId(),
+			Date:       fromTimestamp(notification.GetDate()),
+			Payload:    fromValue(notification.GetPayload()),
+			Expiration: fromTimestamp(notification.GetExpiration()),
+		})
+	}
+	return info
+}
+
+func toDevicePresence(presence *pushy.DevicePresenceResponse) *pushypb.DevicePresenceResponse {
+	res := &pushypb.DevicePresenceResponse{}
+	for _, p := range presence.Presence {
+		res.Presence = append(res.Presence, &pushypb.Presence{
+			Id:         p.ID,
+			Online:     p.Online,
+			LastActive: toTimestamp(p.LastActive),
+		})
+	}
+	return res
+}
+
+func fromDevicePresence(res *pushypb.DevicePresenceResponse) *pushy.DevicePresenceResponse {
+	presence := &pushy.DevicePresenceResponse{}
+	for _, p := range res.GetPresence() {
+		presence.Presence = append(presence.Presence, pushy.Presence{
+			ID:         p.GetId(),
+			Online:     p.GetOnline(),
+			LastActive: fromTimestamp(p.GetLastActive()),
+		})
+	}
+	return presence
+}
+
+func toNotificationStatus(status *pushy.NotificationStatus) (*pushypb.NotificationStatusResponse, error) {
+	payload, err := toValue(status.Push.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &pushypb.NotificationStatusResponse{
+		Push: &pushypb.PushStatus{
+			Date:           toTimestamp(status.Push.Date),
+			Payload:        payload,
+			Expiration:     toTimestamp(status.Push.Expiration),
+			PendingDevices: status.Push.PendingDevices,
+		},
+	}, nil
+}
+
+func fromNotificationStatus(res *pushypb.NotificationStatusResponse) *pushy.NotificationStatus {
+	return &pushy.NotificationStatus{
+		Push: pushy.PushStatus{
+			Date:           fromTimestamp(res.GetPush().GetDate()),
+			Payload:        fromValue(res.GetPush().GetPayload()),
+			Expiration:     fromTimestamp(res.GetPush().GetExpiration()),
+			PendingDevices: res.GetPush().GetPendingDevices(),
+		},
+	}
+}
+
+func toTopics(topics *pushy.TopicsResponse) *pushypb.TopicsResponse {
+	res := &pushypb.TopicsResponse{}
+	for _, topic := range topics.Topics {
+		res.Topics = append(res.Topics, &pushypb.Topic{Name: topic.Name, Subscribers: int64(topic.Subscribers)})
+	}
+	return res
+}
+
+func fromTopics(res *pushypb.TopicsResponse) *pushy.TopicsResponse {
+	topics := &pushy.TopicsResponse{}
+	for _, topic := range res.GetTopics() {
+		topics.Topics = append(topics.Topics, pushy.Topic{Name: topic.GetName(), Subscribers: int(topic.GetSubscribers())})
+	}
+	return topics
+}
+
+func toNotificationResponse(res *pushy.NotificationResponse) *pushypb.NotificationResponse {
+	converted := &pushypb.NotificationResponse{Success: res.Success, Id: res.ID}
+	if info := res.Info; info != nil {
+		converted.Info = &pushypb.NotificationInfo{Devices: int64(info.Devices), Failed: info.Failed}
+	}
+	return converted
+}
+
+func fromNotificationResponse(res *pushypb.NotificationResponse) *pushy.NotificationResponse {
+	converted := &pushy.NotificationResponse{Success: res.GetSuccess(), ID: res.GetId()}
+	if info := res.GetInfo(); info != nil {
+		converted.Info = &pushy.NotificationInfo{Devices: int(info.GetDevices()), Failed: info.GetFailed()}
+	}
+	return converted
+}
+
+func toSendRequest(request pushy.SendNotificationRequest) *pushypb.SendNotificationRequest {
+	ios := request.IOSNotification
+	res := &pushypb.SendNotificationRequest{
+		To:                  request.To,
+		Data:                request.Data,
+		TimeToLive:          int64(request.TimeToLive),
+		IosMutableContent:   request.IOSMutableContent,
+		IosContentAvailable: request.IOSContentAvailable,
+		IosNotification: &pushypb.IOSNotification{
+			Body:         ios.Body,
+			Badge:        int64(ios.Badge),
+			Sound:        ios.Sound,
+			Title:        ios.Title,
+			Category:     ios.Category,
+			LocKey:       ios.LocKey,
+			LocArgs:      ios.LocArgs,
+			TitleLocKey:  ios.TitleLocKey,
+			TitleLocArgs: ios.TitleLocArgs,
+		},
+	}
+	if android := request.AndroidOptions; android != (pushy.AndroidOptions{}) {
+		res.Android = &pushypb.AndroidOptions{
+			Priority:              string(android.Priority),
+			ChannelId:             android.ChannelID,
+			CollapseKey:           android.CollapseKey,
+			RestrictedPackageName: android.RestrictedPackageName,
+			DirectBootOk:          android.DirectBootOK,
+		}
+	}
+	return res
+}
+
+func fromSendRequest(res *pushypb.SendNotificationRequest) pushy.SendNotificationRequest {
+	ios := res.GetIosNotification()
+	request := pushy.SendNotificationRequest{
+		To:                  res.GetTo(),
+		Data:                res.GetData(),
+		TimeToLive:          int(res.GetTimeToLive()),
+		IOSMutableContent:   res.GetIosMutableContent(),
+		IOSContentAvailable: res.GetIosContentAvailable(),
+		IOSNotification: pushy.IOSNotification{
+			Body:         ios.GetBody(),
+			Badge:        int(ios.GetBadge()),
+			Sound:        ios.GetSound(),
+			Title:        ios.GetTitle(),
+			Category:     ios.GetCategory(),
+			LocKey:       ios.GetLocKey(),
+			LocArgs:      ios.GetLocArgs(),
+			TitleLocKey:  ios.GetTitleLocKey(),
+			TitleLocArgs: ios.GetTitleLocArgs(),
+		},
+	}
+	if android := res.GetAndroid(); android != nil {
+		request.AndroidOptions = pushy.AndroidOptions{
+			Priority:              pushy.AndroidPriority(android.GetPriority()),
+			ChannelID:             android.GetChannelId(),
+			CollapseKey:           android.GetCollapseKey(),
+			RestrictedPackageName: android.GetRestrictedPackageName(),
+			DirectBootOK:          android.GetDirectBootOk(),
+		}
+	}
+	return request
+}
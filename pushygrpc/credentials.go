@@ -0,0 +1,25 @@
+package pushygrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// APIKey returns credentials sending key as "authorization: Bearer <key>" with every call,
+// which is how callers authenticate with cmd/pushy-grpc:
+//  conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(tlsCredentials), grpc.WithPerRPCCredentials(pushygrpc.APIKey(key)))
+func APIKey(key string) credentials.PerRPCCredentials {
+	return apiKey(key)
+}
+
+type apiKey string
+
+func (k apiKey) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(k)}, nil
+}
+
+// RequireTransportSecurity is false, so keys can be sent to a server listening on loopback without tls
+func (apiKey) RequireTransportSecurity() bool {
+	return false
+}
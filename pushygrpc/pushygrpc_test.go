@@ -0,0 +1,234 @@
+package pushygrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/fossapps/pushy/pushygrpc"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	_ pushy.IPushyClientWithOptions = (*pushygrpc.Client)(nil)
+	_ pushy.ITopicsClient           = (*pushygrpc.Client)(nil)
+)
+
+// setup starts fake pushy, a gRPC server relaying to it and returns a client connected to that server
+func setup(t *testing.T, token string) (*pushygrpc.Client, *pushytest.Server, func()) {
+	upstream := pushytest.NewServer()
+	upstream.SetAPIKey("SECRET")
+	sdk := pushy.Create(token, upstream.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pushypb.RegisterPushyServiceServer(server, pushygrpc.NewServer(sdk))
+	go server.Serve(listener)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pushygrpc.NewClient(conn, time.Second), upstream, func() {
+		conn.Close()
+		server.Stop()
+		upstream.Close()
+	}
+}
+
+func TestClient_Operations(t *testing.T) {
+	Assert := assert.New(t)
+	client, _, cleanup := setup(t, "SECRET")
+	defer cleanup()
+
+	var meta pushy.ResponseMeta
+	info, pushyErr, err := client.DeviceInfoWithOptions("DEVICE", pushy.WithMeta(&meta))
+	Assert.Nil(pushyErr)
+	Assert.Nil(err)
+	Assert.Equal(1, meta.Attempts)
+	Assert.True(meta.Latency > 0)
+	Assert.Equal(pushy.PlatformAndroid, info.Device.Platform)
+	Assert.Equal(int64(1445207358), info.Device.Date.Unix())
+	Assert.Equal(215, info.Presence.LastActive.SecondsAgo)
+	Assert.Equal(int64(1466600196), info.PendingNotifications[0].Expiration.Unix())
+	Assert.Equal(map[string]interface{}{"message": "Hello World!"}, info.PendingNotifications[0].Payload)
+
+	presence, _, err := client.DevicePresence("DEVICE")
+	Assert.Nil(err)
+	Assert.Equal("DEVICE", presence.Presence[0].ID)
+	Assert.Equal(int64(1429406442), presence.Presence[0].LastActive.Unix())
+
+	notificationStatus, _, err := client.NotificationStatus("PUSH_ID")
+	Assert.Nil(err)
+	Assert.Equal([]string{"DEVICE"}, notificationStatus.Push.PendingDevices)
+	Assert.Equal(int64(1466595935), notificationStatus.Push.Expiration.Unix())
+
+	deleted, _, err := client.DeleteNotification("PUSH_ID")
+	Assert.Nil(err)
+	Assert.True(deleted.Success)
+
+	subscribed, _, err := client.SubscribeToTopic("DEVICE", "news")
+	Assert.Nil(err)
+	Assert.True(subscribed.Success)
+
+	unsubscribed, _, err := client.UnsubscribeFromTopic("DEVICE", "news")
+	Assert.Nil(err)
+	Assert.True(unsubscribed.Success)
+
+	topics, _, err := client.Topics()
+	Assert.Nil(err)
+	Assert.Equal([]pushy.Topic{{Name: "news", Subscribers: 3}}, topics.Topics)
+
+	request, _ := pushy.NewNotificationBuilder("DEVICE").
+		Data(`{"message":"hi"}`).
+		Web(pushy.WebNotification{Title: "hi", URL: "https://example.com"}).
+		Android(pushy.AndroidOptions{Priority: pushy.AndroidPriorityHigh}).
+		Build()
+	sent, _, err := client.NotifyDevice(request)
+	Assert.Nil(err)
+	Assert.Equal("PUSH1", sent.ID)
+}
+
+func TestClient_Errors(t *testing.T) {
+	Assert := assert.New(t)
+	client, _, cleanup := setup(t, "WRONG")
+	defer cleanup()
+
+	info, pushyErr, err := client.DeviceInfo("DEVICE")
+	Assert.Nil(info)
+	Assert.Equal(&pushy.Error{Error: "invalid api key"}, pushyErr)
+	Assert.Contains(err.Error(), "401")
+
+	_, pushyErr, err = client.NotifyDevice(pushy.SendNotificationRequest{})
+	Assert.Nil(pushyErr)
+	Assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, pushyErr, err = client.SubscribeToTopic("DEVICE")
+	Assert.Nil(pushyErr)
+	Assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, _, err = client.NotificationStatus("")
+	Assert.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	sdk := pushy.Create("SECRET", "http://127.0.0.1:1")
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	res, err := pushygrpc.NewServer(sdk).Topics(context.Background(), &pushypb.TopicsRequest{})
+	assert.Nil(t, res)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// a client which doesn't list topics
+	_, err = pushygrpc.NewServer(struct{ pushy.IPushyClientWithOptions }{sdk}).Topics(context.Background(), &pushypb.TopicsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// failing fails every DeviceInfo with err
+type failing struct {
+	pushy.IPushyClientWithOptions
+	err error
+}
+
+func (f failing) DeviceInfoWithOptions(string, ...pushy.CallOption) (*pushy.DeviceInfo, *pushy.Error, error) {
+	return nil, nil, f.err
+}
+
+func TestServer_StatusCodes(t *testing.T) {
+	Assert := assert.New(t)
+	upstream := pushytest.NewServer()
+	defer upstream.Close()
+	sdk := pushy.Create("SECRET", upstream.URL)
+	sdk.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	server := pushygrpc.NewServer(sdk)
+
+	table := []struct {
+		apiKey string
+		status int
+		device string
+		code   codes.Code
+	}{
+		{device: "MISSING", code: codes.NotFound},
+		{apiKey: "OTHER", device: "DEVICE", code: codes.Internal},
+		{status: http.StatusForbidden, device: "DEVICE", code: codes.Internal},
+		{status: http.StatusBadRequest, device: "DEVICE", code: codes.InvalidArgument},
+		{status: http.StatusTooManyRequests, device: "DEVICE", code: codes.ResourceExhausted},
+		{status: http.StatusInternalServerError, device: "DEVICE", code: codes.Unavailable},
+		{status: http.StatusConflict, device: "DEVICE", code: codes.FailedPrecondition},
+	}
+	for _, data := range table {
+		upstream.SetAPIKey(data.apiKey)
+		upstream.SetStatus(data.status)
+		_, err := server.DeviceInfo(context.Background(), &pushypb.DeviceInfoRequest{DeviceId: data.device})
+		Assert.Equal(data.code, status.Code(err), "%d %s", data.status, data.apiKey)
+		Assert.Len(status.Convert(err).Details(), 1, "pushy error is a detail")
+	}
+
+	for err, code := range map[error]codes.Code{
+		context.DeadlineExceeded:                    codes.DeadlineExceeded,
+		fmt.Errorf("wrapped: %w", context.Canceled): codes.Canceled,
+		errors.New("connection refused"):            codes.Unavailable,
+	} {
+		_, got := pushygrpc.NewServer(failing{sdk, err}).DeviceInfo(context.Background(), &pushypb.DeviceInfoRequest{DeviceId: "DEVICE"})
+		Assert.Equal(code, status.Code(got), err.Error())
+	}
+}
+
+func TestClient_NotifyDevices(t *testing.T) {
+	Assert := assert.New(t)
+	client, _, cleanup := setup(t, "SECRET")
+	defer cleanup()
+	results, err := client.NotifyDevices([]pushy.SendNotificationRequest{
+		{To: []string{"A"}},
+		{},
+		{To: []string{"B"}},
+	})
+	Assert.Nil(err)
+	Assert.Len(results, 3)
+	Assert.Equal("PUSH1", results[0].Response.ID)
+	Assert.Nil(results[1].Response)
+	Assert.Contains(results[1].Err.Error(), "to")
+	Assert.Nil(results[2].Err)
+
+	wrong, _, cleanupWrong := setup(t, "WRONG")
+	defer cleanupWrong()
+	results, err = wrong.NotifyDevices([]pushy.SendNotificationRequest{{To: []string{"A"}}})
+	Assert.Nil(err)
+	Assert.Equal("invalid api key", results[0].PushyErr.Error)
+	Assert.NotNil(results[0].Err)
+}
+
+func TestClient_CallOptions(t *testing.T) {
+	Assert := assert.New(t)
+	client, upstream, cleanup := setup(t, "SECRET")
+	defer cleanup()
+
+	_, _, err := client.NotifyDeviceWithOptions(pushy.SendNotificationRequest{To: []string{"A"}}, pushy.WithIdempotencyKey("KEY"))
+	Assert.Nil(err)
+	Assert.Equal("KEY", upstream.Pushes()[0].Header.Get("Idempotency-Key"), "idempotency key is relayed to pushy")
+
+	_, _, err = client.Topics(pushy.WithTimeout(time.Nanosecond))
+	Assert.Equal(codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestClient_HTTPClient(t *testing.T) {
+	client := pushygrpc.NewClient(nil, 0)
+	httpClient := pushy.GetDefaultHTTPClient(time.Second)
+	client.SetHTTPClient(httpClient)
+	assert.Equal(t, httpClient, client.GetHTTPClient())
+}
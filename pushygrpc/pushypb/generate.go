@@ -0,0 +1,11 @@
+// Package pushypb contains protobuf messages and gRPC service generated from pushy.proto.
+// plugin versions are pinned, so regenerating doesn't change code which didn't change in pushy.proto,
+// they are written to the header of generated files. install them with
+//  go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.11
+//  go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+// protoc-gen-go has to match google.golang.org/protobuf in Gopkg.toml. protoc itself isn't pinned,
+// which is why headers of the checked in files say its version is unknown
+package pushypb
+
+//go:generate sh -c "protoc-gen-go --version | grep -qx 'protoc-gen-go v1.36.11' && protoc-gen-go-grpc --version | grep -qx 'protoc-gen-go-grpc 1.5.1' || { echo 'pushypb is generated with protoc-gen-go v1.36.11 and protoc-gen-go-grpc 1.5.1' >&2; exit 1; }"
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pushy.proto
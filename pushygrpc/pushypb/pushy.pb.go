@@ -0,0 +1,1761 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: pushy.proto
+
+// pushy.v1 mirrors pushy.IPushyClient, so services can use pushy through a relay
+// with the same operations they'd use talking to pushy directly.
+
+package pushypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PushyError is attached as status detail when pushy rejected a request
+type PushyError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushyError) Reset() {
+	*x = PushyError{}
+	mi := &file_pushy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushyError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushyError) ProtoMessage() {}
+
+func (x *PushyError) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushyError.ProtoReflect.Descriptor instead.
+func (*PushyError) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PushyError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeviceInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceInfoRequest) Reset() {
+	*x = DeviceInfoRequest{}
+	mi := &file_pushy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceInfoRequest) ProtoMessage() {}
+
+func (x *DeviceInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceInfoRequest.ProtoReflect.Descriptor instead.
+func (*DeviceInfoRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DeviceInfoRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Platform      string                 `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_pushy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Device) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Device) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+type LastActive struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	SecondsAgo    int64                  `protobuf:"varint,2,opt,name=seconds_ago,json=secondsAgo,proto3" json:"seconds_ago,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LastActive) Reset() {
+	*x = LastActive{}
+	mi := &file_pushy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LastActive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LastActive) ProtoMessage() {}
+
+func (x *LastActive) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LastActive.ProtoReflect.Descriptor instead.
+func (*LastActive) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LastActive) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *LastActive) GetSecondsAgo() int64 {
+	if x != nil {
+		return x.SecondsAgo
+	}
+	return 0
+}
+
+type DevicePresenceInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Online        bool                   `protobuf:"varint,1,opt,name=online,proto3" json:"online,omitempty"`
+	LastActive    *LastActive            `protobuf:"bytes,2,opt,name=last_active,json=lastActive,proto3" json:"last_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DevicePresenceInfo) Reset() {
+	*x = DevicePresenceInfo{}
+	mi := &file_pushy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DevicePresenceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DevicePresenceInfo) ProtoMessage() {}
+
+func (x *DevicePresenceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DevicePresenceInfo.ProtoReflect.Descriptor instead.
+func (*DevicePresenceInfo) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DevicePresenceInfo) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *DevicePresenceInfo) GetLastActive() *LastActive {
+	if x != nil {
+		return x.LastActive
+	}
+	return nil
+}
+
+type Notification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Payload       *structpb.Value        `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	Expiration    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expiration,proto3" json:"expiration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	mi := &file_pushy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Notification) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Notification) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Notification) GetPayload() *structpb.Value {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Notification) GetExpiration() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expiration
+	}
+	return nil
+}
+
+type DeviceInfoResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Device               *Device                `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	Subscriptions        []string               `protobuf:"bytes,2,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	Presence             *DevicePresenceInfo    `protobuf:"bytes,3,opt,name=presence,proto3" json:"presence,omitempty"`
+	PendingNotifications []*Notification        `protobuf:"bytes,4,rep,name=pending_notifications,json=pendingNotifications,proto3" json:"pending_notifications,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DeviceInfoResponse) Reset() {
+	*x = DeviceInfoResponse{}
+	mi := &file_pushy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceInfoResponse) ProtoMessage() {}
+
+func (x *DeviceInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceInfoResponse.ProtoReflect.Descriptor instead.
+func (*DeviceInfoResponse) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeviceInfoResponse) GetDevice() *Device {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *DeviceInfoResponse) GetSubscriptions() []string {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
+func (x *DeviceInfoResponse) GetPresence() *DevicePresenceInfo {
+	if x != nil {
+		return x.Presence
+	}
+	return nil
+}
+
+func (x *DeviceInfoResponse) GetPendingNotifications() []*Notification {
+	if x != nil {
+		return x.PendingNotifications
+	}
+	return nil
+}
+
+type DevicePresenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIds     []string               `protobuf:"bytes,1,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DevicePresenceRequest) Reset() {
+	*x = DevicePresenceRequest{}
+	mi := &file_pushy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DevicePresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DevicePresenceRequest) ProtoMessage() {}
+
+func (x *DevicePresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DevicePresenceRequest.ProtoReflect.Descriptor instead.
+func (*DevicePresenceRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DevicePresenceRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+type Presence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Online        bool                   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`
+	LastActive    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_active,json=lastActive,proto3" json:"last_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Presence) Reset() {
+	*x = Presence{}
+	mi := &file_pushy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Presence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Presence) ProtoMessage() {}
+
+func (x *Presence) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Presence.ProtoReflect.Descriptor instead.
+func (*Presence) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Presence) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Presence) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *Presence) GetLastActive() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActive
+	}
+	return nil
+}
+
+type DevicePresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Presence      []*Presence            `protobuf:"bytes,1,rep,name=presence,proto3" json:"presence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DevicePresenceResponse) Reset() {
+	*x = DevicePresenceResponse{}
+	mi := &file_pushy_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DevicePresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DevicePresenceResponse) ProtoMessage() {}
+
+func (x *DevicePresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DevicePresenceResponse.ProtoReflect.Descriptor instead.
+func (*DevicePresenceResponse) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DevicePresenceResponse) GetPresence() []*Presence {
+	if x != nil {
+		return x.Presence
+	}
+	return nil
+}
+
+type NotificationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PushId        string                 `protobuf:"bytes,1,opt,name=push_id,json=pushId,proto3" json:"push_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationStatusRequest) Reset() {
+	*x = NotificationStatusRequest{}
+	mi := &file_pushy_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationStatusRequest) ProtoMessage() {}
+
+func (x *NotificationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationStatusRequest.ProtoReflect.Descriptor instead.
+func (*NotificationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *NotificationStatusRequest) GetPushId() string {
+	if x != nil {
+		return x.PushId
+	}
+	return ""
+}
+
+type PushStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Date           *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Payload        *structpb.Value        `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Expiration     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expiration,proto3" json:"expiration,omitempty"`
+	PendingDevices []string               `protobuf:"bytes,4,rep,name=pending_devices,json=pendingDevices,proto3" json:"pending_devices,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PushStatus) Reset() {
+	*x = PushStatus{}
+	mi := &file_pushy_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushStatus) ProtoMessage() {}
+
+func (x *PushStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushStatus.ProtoReflect.Descriptor instead.
+func (*PushStatus) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PushStatus) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *PushStatus) GetPayload() *structpb.Value {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *PushStatus) GetExpiration() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expiration
+	}
+	return nil
+}
+
+func (x *PushStatus) GetPendingDevices() []string {
+	if x != nil {
+		return x.PendingDevices
+	}
+	return nil
+}
+
+type NotificationStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Push          *PushStatus            `protobuf:"bytes,1,opt,name=push,proto3" json:"push,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationStatusResponse) Reset() {
+	*x = NotificationStatusResponse{}
+	mi := &file_pushy_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationStatusResponse) ProtoMessage() {}
+
+func (x *NotificationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationStatusResponse.ProtoReflect.Descriptor instead.
+func (*NotificationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *NotificationStatusResponse) GetPush() *PushStatus {
+	if x != nil {
+		return x.Push
+	}
+	return nil
+}
+
+type DeleteNotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PushId        string                 `protobuf:"bytes,1,opt,name=push_id,json=pushId,proto3" json:"push_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNotificationRequest) Reset() {
+	*x = DeleteNotificationRequest{}
+	mi := &file_pushy_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNotificationRequest) ProtoMessage() {}
+
+func (x *DeleteNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNotificationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteNotificationRequest) GetPushId() string {
+	if x != nil {
+		return x.PushId
+	}
+	return ""
+}
+
+type TopicSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Topics        []string               `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicSubscriptionRequest) Reset() {
+	*x = TopicSubscriptionRequest{}
+	mi := &file_pushy_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicSubscriptionRequest) ProtoMessage() {}
+
+func (x *TopicSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*TopicSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TopicSubscriptionRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *TopicSubscriptionRequest) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+type SimpleSuccess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimpleSuccess) Reset() {
+	*x = SimpleSuccess{}
+	mi := &file_pushy_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimpleSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimpleSuccess) ProtoMessage() {}
+
+func (x *SimpleSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimpleSuccess.ProtoReflect.Descriptor instead.
+func (*SimpleSuccess) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SimpleSuccess) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type TopicsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicsRequest) Reset() {
+	*x = TopicsRequest{}
+	mi := &file_pushy_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicsRequest) ProtoMessage() {}
+
+func (x *TopicsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicsRequest.ProtoReflect.Descriptor instead.
+func (*TopicsRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{16}
+}
+
+type Topic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Subscribers   int64                  `protobuf:"varint,2,opt,name=subscribers,proto3" json:"subscribers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topic) Reset() {
+	*x = Topic{}
+	mi := &file_pushy_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topic) ProtoMessage() {}
+
+func (x *Topic) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topic.ProtoReflect.Descriptor instead.
+func (*Topic) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Topic) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Topic) GetSubscribers() int64 {
+	if x != nil {
+		return x.Subscribers
+	}
+	return 0
+}
+
+type TopicsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topics        []*Topic               `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicsResponse) Reset() {
+	*x = TopicsResponse{}
+	mi := &file_pushy_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicsResponse) ProtoMessage() {}
+
+func (x *TopicsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicsResponse.ProtoReflect.Descriptor instead.
+func (*TopicsResponse) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TopicsResponse) GetTopics() []*Topic {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+type IOSNotification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Body          string                 `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Badge         int64                  `protobuf:"varint,2,opt,name=badge,proto3" json:"badge,omitempty"`
+	Sound         string                 `protobuf:"bytes,3,opt,name=sound,proto3" json:"sound,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Category      string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	LocKey        string                 `protobuf:"bytes,6,opt,name=loc_key,json=locKey,proto3" json:"loc_key,omitempty"`
+	LocArgs       []string               `protobuf:"bytes,7,rep,name=loc_args,json=locArgs,proto3" json:"loc_args,omitempty"`
+	TitleLocKey   string                 `protobuf:"bytes,8,opt,name=title_loc_key,json=titleLocKey,proto3" json:"title_loc_key,omitempty"`
+	TitleLocArgs  []string               `protobuf:"bytes,9,rep,name=title_loc_args,json=titleLocArgs,proto3" json:"title_loc_args,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IOSNotification) Reset() {
+	*x = IOSNotification{}
+	mi := &file_pushy_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IOSNotification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IOSNotification) ProtoMessage() {}
+
+func (x *IOSNotification) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IOSNotification.ProtoReflect.Descriptor instead.
+func (*IOSNotification) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *IOSNotification) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *IOSNotification) GetBadge() int64 {
+	if x != nil {
+		return x.Badge
+	}
+	return 0
+}
+
+func (x *IOSNotification) GetSound() string {
+	if x != nil {
+		return x.Sound
+	}
+	return ""
+}
+
+func (x *IOSNotification) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *IOSNotification) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *IOSNotification) GetLocKey() string {
+	if x != nil {
+		return x.LocKey
+	}
+	return ""
+}
+
+func (x *IOSNotification) GetLocArgs() []string {
+	if x != nil {
+		return x.LocArgs
+	}
+	return nil
+}
+
+func (x *IOSNotification) GetTitleLocKey() string {
+	if x != nil {
+		return x.TitleLocKey
+	}
+	return ""
+}
+
+func (x *IOSNotification) GetTitleLocArgs() []string {
+	if x != nil {
+		return x.TitleLocArgs
+	}
+	return nil
+}
+
+type AndroidOptions struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Priority              string                 `protobuf:"bytes,1,opt,name=priority,proto3" json:"priority,omitempty"`
+	ChannelId             string                 `protobuf:"bytes,2,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	CollapseKey           string                 `protobuf:"bytes,3,opt,name=collapse_key,json=collapseKey,proto3" json:"collapse_key,omitempty"`
+	RestrictedPackageName string                 `protobuf:"bytes,4,opt,name=restricted_package_name,json=restrictedPackageName,proto3" json:"restricted_package_name,omitempty"`
+	DirectBootOk          bool                   `protobuf:"varint,5,opt,name=direct_boot_ok,json=directBootOk,proto3" json:"direct_boot_ok,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *AndroidOptions) Reset() {
+	*x = AndroidOptions{}
+	mi := &file_pushy_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AndroidOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AndroidOptions) ProtoMessage() {}
+
+func (x *AndroidOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AndroidOptions.ProtoReflect.Descriptor instead.
+func (*AndroidOptions) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AndroidOptions) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *AndroidOptions) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *AndroidOptions) GetCollapseKey() string {
+	if x != nil {
+		return x.CollapseKey
+	}
+	return ""
+}
+
+func (x *AndroidOptions) GetRestrictedPackageName() string {
+	if x != nil {
+		return x.RestrictedPackageName
+	}
+	return ""
+}
+
+func (x *AndroidOptions) GetDirectBootOk() bool {
+	if x != nil {
+		return x.DirectBootOk
+	}
+	return false
+}
+
+type SendNotificationRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	To                  []string               `protobuf:"bytes,1,rep,name=to,proto3" json:"to,omitempty"`
+	Data                string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	TimeToLive          int64                  `protobuf:"varint,3,opt,name=time_to_live,json=timeToLive,proto3" json:"time_to_live,omitempty"`
+	IosMutableContent   bool                   `protobuf:"varint,4,opt,name=ios_mutable_content,json=iosMutableContent,proto3" json:"ios_mutable_content,omitempty"`
+	IosContentAvailable bool                   `protobuf:"varint,5,opt,name=ios_content_available,json=iosContentAvailable,proto3" json:"ios_content_available,omitempty"`
+	IosNotification     *IOSNotification       `protobuf:"bytes,6,opt,name=ios_notification,json=iosNotification,proto3" json:"ios_notification,omitempty"`
+	Android             *AndroidOptions        `protobuf:"bytes,8,opt,name=android,proto3" json:"android,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SendNotificationRequest) Reset() {
+	*x = SendNotificationRequest{}
+	mi := &file_pushy_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendNotificationRequest) ProtoMessage() {}
+
+func (x *SendNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendNotificationRequest.ProtoReflect.Descriptor instead.
+func (*SendNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SendNotificationRequest) GetTo() []string {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SendNotificationRequest) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *SendNotificationRequest) GetTimeToLive() int64 {
+	if x != nil {
+		return x.TimeToLive
+	}
+	return 0
+}
+
+func (x *SendNotificationRequest) GetIosMutableContent() bool {
+	if x != nil {
+		return x.IosMutableContent
+	}
+	return false
+}
+
+func (x *SendNotificationRequest) GetIosContentAvailable() bool {
+	if x != nil {
+		return x.IosContentAvailable
+	}
+	return false
+}
+
+func (x *SendNotificationRequest) GetIosNotification() *IOSNotification {
+	if x != nil {
+		return x.IosNotification
+	}
+	return nil
+}
+
+func (x *SendNotificationRequest) GetAndroid() *AndroidOptions {
+	if x != nil {
+		return x.Android
+	}
+	return nil
+}
+
+type NotificationInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       int64                  `protobuf:"varint,1,opt,name=devices,proto3" json:"devices,omitempty"`
+	Failed        []string               `protobuf:"bytes,2,rep,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationInfo) Reset() {
+	*x = NotificationInfo{}
+	mi := &file_pushy_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationInfo) ProtoMessage() {}
+
+func (x *NotificationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationInfo.ProtoReflect.Descriptor instead.
+func (*NotificationInfo) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *NotificationInfo) GetDevices() int64 {
+	if x != nil {
+		return x.Devices
+	}
+	return 0
+}
+
+func (x *NotificationInfo) GetFailed() []string {
+	if x != nil {
+		return x.Failed
+	}
+	return nil
+}
+
+type NotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Info          *NotificationInfo      `protobuf:"bytes,3,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationResponse) Reset() {
+	*x = NotificationResponse{}
+	mi := &file_pushy_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationResponse) ProtoMessage() {}
+
+func (x *NotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationResponse.ProtoReflect.Descriptor instead.
+func (*NotificationResponse) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *NotificationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *NotificationResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NotificationResponse) GetInfo() *NotificationInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type BatchSendRequest struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Requests      []*SendNotificationRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSendRequest) Reset() {
+	*x = BatchSendRequest{}
+	mi := &file_pushy_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSendRequest) ProtoMessage() {}
+
+func (x *BatchSendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSendRequest.ProtoReflect.Descriptor instead.
+func (*BatchSendRequest) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BatchSendRequest) GetRequests() []*SendNotificationRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// BatchSendResult is the outcome of one request, in the same order requests were given
+type BatchSendResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Response      *NotificationResponse  `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	PushyError    *PushyError            `protobuf:"bytes,2,opt,name=pushy_error,json=pushyError,proto3" json:"pushy_error,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSendResult) Reset() {
+	*x = BatchSendResult{}
+	mi := &file_pushy_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSendResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSendResult) ProtoMessage() {}
+
+func (x *BatchSendResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSendResult.ProtoReflect.Descriptor instead.
+func (*BatchSendResult) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BatchSendResult) GetResponse() *NotificationResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *BatchSendResult) GetPushyError() *PushyError {
+	if x != nil {
+		return x.PushyError
+	}
+	return nil
+}
+
+func (x *BatchSendResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchSendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchSendResult     `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSendResponse) Reset() {
+	*x = BatchSendResponse{}
+	mi := &file_pushy_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSendResponse) ProtoMessage() {}
+
+func (x *BatchSendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pushy_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSendResponse.ProtoReflect.Descriptor instead.
+func (*BatchSendResponse) Descriptor() ([]byte, []int) {
+	return file_pushy_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BatchSendResponse) GetResults() []*BatchSendResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_pushy_proto protoreflect.FileDescriptor
+
+const file_pushy_proto_rawDesc = "" +
+	"\n" +
+	"\vpushy.proto\x12\bpushy.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\"\n" +
+	"\n" +
+	"PushyError\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"0\n" +
+	"\x11DeviceInfoRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"T\n" +
+	"\x06Device\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\"]\n" +
+	"\n" +
+	"LastActive\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x1f\n" +
+	"\vseconds_ago\x18\x02 \x01(\x03R\n" +
+	"secondsAgo\"c\n" +
+	"\x12DevicePresenceInfo\x12\x16\n" +
+	"\x06online\x18\x01 \x01(\bR\x06online\x125\n" +
+	"\vlast_active\x18\x02 \x01(\v2\x14.pushy.v1.LastActiveR\n" +
+	"lastActive\"\xbc\x01\n" +
+	"\fNotification\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12.\n" +
+	"\x04date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x120\n" +
+	"\apayload\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\apayload\x12:\n" +
+	"\n" +
+	"expiration\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expiration\"\xeb\x01\n" +
+	"\x12DeviceInfoResponse\x12(\n" +
+	"\x06device\x18\x01 \x01(\v2\x10.pushy.v1.DeviceR\x06device\x12$\n" +
+	"\rsubscriptions\x18\x02 \x03(\tR\rsubscriptions\x128\n" +
+	"\bpresence\x18\x03 \x01(\v2\x1c.pushy.v1.DevicePresenceInfoR\bpresence\x12K\n" +
+	"\x15pending_notifications\x18\x04 \x03(\v2\x16.pushy.v1.NotificationR\x14pendingNotifications\"6\n" +
+	"\x15DevicePresenceRequest\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\tR\tdeviceIds\"o\n" +
+	"\bPresence\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06online\x18\x02 \x01(\bR\x06online\x12;\n" +
+	"\vlast_active\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastActive\"H\n" +
+	"\x16DevicePresenceResponse\x12.\n" +
+	"\bpresence\x18\x01 \x03(\v2\x12.pushy.v1.PresenceR\bpresence\"4\n" +
+	"\x19NotificationStatusRequest\x12\x17\n" +
+	"\apush_id\x18\x01 \x01(\tR\x06pushId\"\xd3\x01\n" +
+	"\n" +
+	"PushStatus\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x120\n" +
+	"\apayload\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\apayload\x12:\n" +
+	"\n" +
+	"expiration\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expiration\x12'\n" +
+	"\x0fpending_devices\x18\x04 \x03(\tR\x0ependingDevices\"F\n" +
+	"\x1aNotificationStatusResponse\x12(\n" +
+	"\x04push\x18\x01 \x01(\v2\x14.pushy.v1.PushStatusR\x04push\"4\n" +
+	"\x19DeleteNotificationRequest\x12\x17\n" +
+	"\apush_id\x18\x01 \x01(\tR\x06pushId\"O\n" +
+	"\x18TopicSubscriptionRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06topics\x18\x02 \x03(\tR\x06topics\")\n" +
+	"\rSimpleSuccess\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x0f\n" +
+	"\rTopicsRequest\"=\n" +
+	"\x05Topic\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vsubscribers\x18\x02 \x01(\x03R\vsubscribers\"9\n" +
+	"\x0eTopicsResponse\x12'\n" +
+	"\x06topics\x18\x01 \x03(\v2\x0f.pushy.v1.TopicR\x06topics\"\x81\x02\n" +
+	"\x0fIOSNotification\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\tR\x04body\x12\x14\n" +
+	"\x05badge\x18\x02 \x01(\x03R\x05badge\x12\x14\n" +
+	"\x05sound\x18\x03 \x01(\tR\x05sound\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12\x17\n" +
+	"\aloc_key\x18\x06 \x01(\tR\x06locKey\x12\x19\n" +
+	"\bloc_args\x18\a \x03(\tR\alocArgs\x12\"\n" +
+	"\rtitle_loc_key\x18\b \x01(\tR\vtitleLocKey\x12$\n" +
+	"\x0etitle_loc_args\x18\t \x03(\tR\ftitleLocArgs\"\xcc\x01\n" +
+	"\x0eAndroidOptions\x12\x1a\n" +
+	"\bpriority\x18\x01 \x01(\tR\bpriority\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x02 \x01(\tR\tchannelId\x12!\n" +
+	"\fcollapse_key\x18\x03 \x01(\tR\vcollapseKey\x126\n" +
+	"\x17restricted_package_name\x18\x04 \x01(\tR\x15restrictedPackageName\x12$\n" +
+	"\x0edirect_boot_ok\x18\x05 \x01(\bR\fdirectBootOk\"\xc8\x02\n" +
+	"\x17SendNotificationRequest\x12\x0e\n" +
+	"\x02to\x18\x01 \x03(\tR\x02to\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\x12 \n" +
+	"\ftime_to_live\x18\x03 \x01(\x03R\n" +
+	"timeToLive\x12.\n" +
+	"\x13ios_mutable_content\x18\x04 \x01(\bR\x11iosMutableContent\x122\n" +
+	"\x15ios_content_available\x18\x05 \x01(\bR\x13iosContentAvailable\x12D\n" +
+	"\x10ios_notification\x18\x06 \x01(\v2\x19.pushy.v1.IOSNotificationR\x0fiosNotification\x122\n" +
+	"\aandroid\x18\b \x01(\v2\x18.pushy.v1.AndroidOptionsR\aandroidJ\x04\b\a\x10\bR\x03web\"D\n" +
+	"\x10NotificationInfo\x12\x18\n" +
+	"\adevices\x18\x01 \x01(\x03R\adevices\x12\x16\n" +
+	"\x06failed\x18\x02 \x03(\tR\x06failed\"p\n" +
+	"\x14NotificationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12.\n" +
+	"\x04info\x18\x03 \x01(\v2\x1a.pushy.v1.NotificationInfoR\x04info\"Q\n" +
+	"\x10BatchSendRequest\x12=\n" +
+	"\brequests\x18\x01 \x03(\v2!.pushy.v1.SendNotificationRequestR\brequests\"\x9a\x01\n" +
+	"\x0fBatchSendResult\x12:\n" +
+	"\bresponse\x18\x01 \x01(\v2\x1e.pushy.v1.NotificationResponseR\bresponse\x125\n" +
+	"\vpushy_error\x18\x02 \x01(\v2\x14.pushy.v1.PushyErrorR\n" +
+	"pushyError\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"H\n" +
+	"\x11BatchSendResponse\x123\n" +
+	"\aresults\x18\x01 \x03(\v2\x19.pushy.v1.BatchSendResultR\aresults2\xe1\x05\n" +
+	"\fPushyService\x12G\n" +
+	"\n" +
+	"DeviceInfo\x12\x1b.pushy.v1.DeviceInfoRequest\x1a\x1c.pushy.v1.DeviceInfoResponse\x12S\n" +
+	"\x0eDevicePresence\x12\x1f.pushy.v1.DevicePresenceRequest\x1a .pushy.v1.DevicePresenceResponse\x12_\n" +
+	"\x12NotificationStatus\x12#.pushy.v1.NotificationStatusRequest\x1a$.pushy.v1.NotificationStatusResponse\x12R\n" +
+	"\x12DeleteNotification\x12#.pushy.v1.DeleteNotificationRequest\x1a\x17.pushy.v1.SimpleSuccess\x12O\n" +
+	"\x10SubscribeToTopic\x12\".pushy.v1.TopicSubscriptionRequest\x1a\x17.pushy.v1.SimpleSuccess\x12S\n" +
+	"\x14UnsubscribeFromTopic\x12\".pushy.v1.TopicSubscriptionRequest\x1a\x17.pushy.v1.SimpleSuccess\x12;\n" +
+	"\x06Topics\x12\x17.pushy.v1.TopicsRequest\x1a\x18.pushy.v1.TopicsResponse\x12Q\n" +
+	"\fNotifyDevice\x12!.pushy.v1.SendNotificationRequest\x1a\x1e.pushy.v1.NotificationResponse\x12H\n" +
+	"\rNotifyDevices\x12\x1a.pushy.v1.BatchSendRequest\x1a\x1b.pushy.v1.BatchSendResponseB-Z+github.com/fossapps/pushy/pushygrpc/pushypbb\x06proto3"
+
+var (
+	file_pushy_proto_rawDescOnce sync.Once
+	file_pushy_proto_rawDescData []byte
+)
+
+func file_pushy_proto_rawDescGZIP() []byte {
+	file_pushy_proto_rawDescOnce.Do(func() {
+		file_pushy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pushy_proto_rawDesc), len(file_pushy_proto_rawDesc)))
+	})
+	return file_pushy_proto_rawDescData
+}
+
+var file_pushy_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_pushy_proto_goTypes = []any{
+	(*PushyError)(nil),                 // 0: pushy.v1.PushyError
+	(*DeviceInfoRequest)(nil),          // 1: pushy.v1.DeviceInfoRequest
+	(*Device)(nil),                     // 2: pushy.v1.Device
+	(*LastActive)(nil),                 // 3: pushy.v1.LastActive
+	(*DevicePresenceInfo)(nil),         // 4: pushy.v1.DevicePresenceInfo
+	(*Notification)(nil),               // 5: pushy.v1.Notification
+	(*DeviceInfoResponse)(nil),         // 6: pushy.v1.DeviceInfoResponse
+	(*DevicePresenceRequest)(nil),      // 7: pushy.v1.DevicePresenceRequest
+	(*Presence)(nil),                   // 8: pushy.v1.Presence
+	(*DevicePresenceResponse)(nil),     // 9: pushy.v1.DevicePresenceResponse
+	(*NotificationStatusRequest)(nil),  // 10: pushy.v1.NotificationStatusRequest
+	(*PushStatus)(nil),                 // 11: pushy.v1.PushStatus
+	(*NotificationStatusResponse)(nil), // 12: pushy.v1.NotificationStatusResponse
+	(*DeleteNotificationRequest)(nil),  // 13: pushy.v1.DeleteNotificationRequest
+	(*TopicSubscriptionRequest)(nil),   // 14: pushy.v1.TopicSubscriptionRequest
+	(*SimpleSuccess)(nil),              // 15: pushy.v1.SimpleSuccess
+	(*TopicsRequest)(nil),              // 16: pushy.v1.TopicsRequest
+	(*Topic)(nil),                      // 17: pushy.v1.Topic
+	(*TopicsResponse)(nil),             // 18: pushy.v1.TopicsResponse
+	(*IOSNotification)(nil),            // 19: pushy.v1.IOSNotification
+	(*AndroidOptions)(nil),             // 20: pushy.v1.AndroidOptions
+	(*SendNotificationRequest)(nil),    // 21: pushy.v1.SendNotificationRequest
+	(*NotificationInfo)(nil),           // 22: pushy.v1.NotificationInfo
+	(*NotificationResponse)(nil),       // 23: pushy.v1.NotificationResponse
+	(*BatchSendRequest)(nil),           // 24: pushy.v1.BatchSendRequest
+	(*BatchSendResult)(nil),            // 25: pushy.v1.BatchSendResult
+	(*BatchSendResponse)(nil),          // 26: pushy.v1.BatchSendResponse
+	(*timestamppb.Timestamp)(nil),      // 27: google.protobuf.Timestamp
+	(*structpb.Value)(nil),             // 28: google.protobuf.Value
+}
+var file_pushy_proto_depIdxs = []int32{
+	27, // 0: pushy.v1.Device.date:type_name -> google.protobuf.Timestamp
+	27, // 1: pushy.v1.LastActive.date:type_name -> google.protobuf.Timestamp
+	3,  // 2: pushy.v1.DevicePresenceInfo.last_active:type_name -> pushy.v1.LastActive
+	27, // 3: pushy.v1.Notification.date:type_name -> google.protobuf.Timestamp
+	28, // 4: pushy.v1.Notification.payload:type_name -> google.protobuf.Value
+	27, // 5: pushy.v1.Notification.expiration:type_name -> google.protobuf.Timestamp
+	2,  // 6: pushy.v1.DeviceInfoResponse.device:type_name -> pushy.v1.Device
+	4,  // 7: pushy.v1.DeviceInfoResponse.presence:type_name -> pushy.v1.DevicePresenceInfo
+	5,  // 8: pushy.v1.DeviceInfoResponse.pending_notifications:type_name -> pushy.v1.Notification
+	27, // 9: pushy.v1.Presence.last_active:type_name -> google.protobuf.Timestamp
+	8,  // 10: pushy.v1.DevicePresenceResponse.presence:type_name -> pushy.v1.Presence
+	27, // 11: pushy.v1.PushStatus.date:type_name -> google.protobuf.Timestamp
+	28, // 12: pushy.v1.PushStatus.payload:type_name -> google.protobuf.Value
+	27, // 13: pushy.v1.PushStatus.expiration:type_name -> google.protobuf.Timestamp
+	11, // 14: pushy.v1.NotificationStatusResponse.push:type_name -> pushy.v1.PushStatus
+	17, // 15: pushy.v1.TopicsResponse.topics:type_name -> pushy.v1.Topic
+	19, // 16: pushy.v1.SendNotificationRequest.ios_notification:type_name -> pushy.v1.IOSNotification
+	20, // 17: pushy.v1.SendNotificationRequest.android:type_name -> pushy.v1.AndroidOptions
+	22, // 18: pushy.v1.NotificationResponse.info:type_name -> pushy.v1.NotificationInfo
+	21, // 19: pushy.v1.BatchSendRequest.requests:type_name -> pushy.v1.SendNotificationRequest
+	23, // 20: pushy.v1.BatchSendResult.response:type_name -> pushy.v1.NotificationResponse
+	0,  // 21: pushy.v1.BatchSendResult.pushy_error:type_name -> pushy.v1.PushyError
+	25, // 22: pushy.v1.BatchSendResponse.results:type_name -> pushy.v1.BatchSendResult
+	1,  // 23: pushy.v1.PushyService.DeviceInfo:input_type -> pushy.v1.DeviceInfoRequest
+	7,  // 24: pushy.v1.PushyService.DevicePresence:input_type -> pushy.v1.DevicePresenceRequest
+	10, // 25: pushy.v1.PushyService.NotificationStatus:input_type -> pushy.v1.NotificationStatusRequest
+	13, // 26: pushy.v1.PushyService.DeleteNotification:input_type -> pushy.v1.DeleteNotificationRequest
+	14, // 27: pushy.v1.PushyService.SubscribeToTopic:input_type -> pushy.v1.TopicSubscriptionRequest
+	14, // 28: pushy.v1.PushyService.UnsubscribeFromTopic:input_type -> pushy.v1.TopicSubscriptionRequest
+	16, // 29: pushy.v1.PushyService.Topics:input_type -> pushy.v1.TopicsRequest
+	21, // 30: pushy.v1.PushyService.NotifyDevice:input_type -> pushy.v1.SendNotificationRequest
+	24, // 31: pushy.v1.PushyService.NotifyDevices:input_type -> pushy.v1.BatchSendRequest
+	6,  // 32: pushy.v1.PushyService.DeviceInfo:output_type -> pushy.v1.DeviceInfoResponse
+	9,  // 33: pushy.v1.PushyService.DevicePresence:output_type -> pushy.v1.DevicePresenceResponse
+	12, // 34: pushy.v1.PushyService.NotificationStatus:output_type -> pushy.v1.NotificationStatusResponse
+	15, // 35: pushy.v1.PushyService.DeleteNotification:output_type -> pushy.v1.SimpleSuccess
+	15, // 36: pushy.v1.PushyService.SubscribeToTopic:output_type -> pushy.v1.SimpleSuccess
+	15, // 37: pushy.v1.PushyService.UnsubscribeFromTopic:output_type -> pushy.v1.SimpleSuccess
+	18, // 38: pushy.v1.PushyService.Topics:output_type -> pushy.v1.TopicsResponse
+	23, // 39: pushy.v1.PushyService.NotifyDevice:output_type -> pushy.v1.NotificationResponse
+	26, // 40: pushy.v1.PushyService.NotifyDevices:output_type -> pushy.v1.BatchSendResponse
+	32, // [32:41] is the sub-list for method output_type
+	23, // [23:32] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_pushy_proto_init() }
+func file_pushy_proto_init() {
+	if File_pushy_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pushy_proto_rawDesc), len(file_pushy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pushy_proto_goTypes,
+		DependencyIndexes: file_pushy_proto_depIdxs,
+		MessageInfos:      file_pushy_proto_msgTypes,
+	}.Build()
+	File_pushy_proto = out.File
+	file_pushy_proto_goTypes = nil
+	file_pushy_proto_depIdxs = nil
+}
@@ -0,0 +1,430 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pushy.proto
+
+// pushy.v1 mirrors pushy.IPushyClient, so services can use pushy through a relay
+// with the same operations they'd use talking to pushy directly.
+
+package pushypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PushyService_DeviceInfo_FullMethodName           = "/pushy.v1.PushyService/DeviceInfo"
+	PushyService_DevicePresence_FullMethodName       = "/pushy.v1.PushyService/DevicePresence"
+	PushyService_NotificationStatus_FullMethodName   = "/pushy.v1.PushyService/NotificationStatus"
+	PushyService_DeleteNotification_FullMethodName   = "/pushy.v1.PushyService/DeleteNotification"
+	PushyService_SubscribeToTopic_FullMethodName     = "/pushy.v1.PushyService/SubscribeToTopic"
+	PushyService_UnsubscribeFromTopic_FullMethodName = "/pushy.v1.PushyService/UnsubscribeFromTopic"
+	PushyService_Topics_FullMethodName               = "/pushy.v1.PushyService/Topics"
+	PushyService_NotifyDevice_FullMethodName         = "/pushy.v1.PushyService/NotifyDevice"
+	PushyService_NotifyDevices_FullMethodName        = "/pushy.v1.PushyService/NotifyDevices"
+)
+
+// PushyServiceClient is the client API for PushyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PushyServiceClient interface {
+	DeviceInfo(ctx context.Context, in *DeviceInfoRequest, opts ...grpc.CallOption) (*DeviceInfoResponse, error)
+	DevicePresence(ctx context.Context, in *DevicePresenceRequest, opts ...grpc.CallOption) (*DevicePresenceResponse, error)
+	NotificationStatus(ctx context.Context, in *NotificationStatusRequest, opts ...grpc.CallOption) (*NotificationStatusResponse, error)
+	DeleteNotification(ctx context.Context, in *DeleteNotificationRequest, opts ...grpc.CallOption) (*SimpleSuccess, error)
+	SubscribeToTopic(ctx context.Context, in *TopicSubscriptionRequest, opts ...grpc.CallOption) (*SimpleSuccess, error)
+	UnsubscribeFromTopic(ctx context.Context, in *TopicSubscriptionRequest, opts ...grpc.CallOption) (*SimpleSuccess, error)
+	Topics(ctx context.Context, in *TopicsRequest, opts ...grpc.CallOption) (*TopicsResponse, error)
+	NotifyDevice(ctx context.Context, in *SendNotificationRequest, opts ...grpc.CallOption) (*NotificationResponse, error)
+	// NotifyDevices sends every request separately, one failing doesn't stop the others
+	NotifyDevices(ctx context.Context, in *BatchSendRequest, opts ...grpc.CallOption) (*BatchSendResponse, error)
+}
+
+type pushyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPushyServiceClient(cc grpc.ClientConnInterface) PushyServiceClient {
+	return &pushyServiceClient{cc}
+}
+
+func (c *pushyServiceClient) DeviceInfo(ctx context.Context, in *DeviceInfoRequest, opts ...grpc.CallOption) (*DeviceInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceInfoResponse)
+	err := c.cc.Invoke(ctx, PushyService_DeviceInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) DevicePresence(ctx context.Context, in *DevicePresenceRequest, opts ...grpc.CallOption) (*DevicePresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DevicePresenceResponse)
+	err := c.cc.Invoke(ctx, PushyService_DevicePresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) NotificationStatus(ctx context.Context, in *NotificationStatusRequest, opts ...grpc.CallOption) (*NotificationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationStatusResponse)
+	err := c.cc.Invoke(ctx, PushyService_NotificationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) DeleteNotification(ctx context.Context, in *DeleteNotificationRequest, opts ...grpc.CallOption) (*SimpleSuccess, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleSuccess)
+	err := c.cc.Invoke(ctx, PushyService_DeleteNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) SubscribeToTopic(ctx context.Context, in *TopicSubscriptionRequest, opts ...grpc.CallOption) (*SimpleSuccess, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleSuccess)
+	err := c.cc.Invoke(ctx, PushyService_SubscribeToTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) UnsubscribeFromTopic(ctx context.Context, in *TopicSubscriptionRequest, opts ...grpc.CallOption) (*SimpleSuccess, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleSuccess)
+	err := c.cc.Invoke(ctx, PushyService_UnsubscribeFromTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) Topics(ctx context.Context, in *TopicsRequest, opts ...grpc.CallOption) (*TopicsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopicsResponse)
+	err := c.cc.Invoke(ctx, PushyService_Topics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) NotifyDevice(ctx context.Context, in *SendNotificationRequest, opts ...grpc.CallOption) (*NotificationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationResponse)
+	err := c.cc.Invoke(ctx, PushyService_NotifyDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushyServiceClient) NotifyDevices(ctx context.Context, in *BatchSendRequest, opts ...grpc.CallOption) (*BatchSendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchSendResponse)
+	err := c.cc.Invoke(ctx, PushyService_NotifyDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PushyServiceServer is the server API for PushyService service.
+// All implementations must embed UnimplementedPushyServiceServer
+// for forward compatibility.
+type PushyServiceServer interface {
+	DeviceInfo(context.Context, *DeviceInfoRequest) (*DeviceInfoResponse, error)
+	DevicePresence(context.Context, *DevicePresenceRequest) (*DevicePresenceResponse, error)
+	NotificationStatus(context.Context, *NotificationStatusRequest) (*NotificationStatusResponse, error)
+	DeleteNotification(context.Context, *DeleteNotificationRequest) (*SimpleSuccess, error)
+	SubscribeToTopic(context.Context, *TopicSubscriptionRequest) (*SimpleSuccess, error)
+	UnsubscribeFromTopic(context.Context, *TopicSubscriptionRequest) (*SimpleSuccess, error)
+	Topics(context.Context, *TopicsRequest) (*TopicsResponse, error)
+	NotifyDevice(context.Context, *SendNotificationRequest) (*NotificationResponse, error)
+	// NotifyDevices sends every request separately, one failing doesn't stop the others
+	NotifyDevices(context.Context, *BatchSendRequest) (*BatchSendResponse, error)
+	mustEmbedUnimplementedPushyServiceServer()
+}
+
+// UnimplementedPushyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPushyServiceServer struct{}
+
+func (UnimplementedPushyServiceServer) DeviceInfo(context.Context, *DeviceInfoRequest) (*DeviceInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeviceInfo not implemented")
+}
+func (UnimplementedPushyServiceServer) DevicePresence(context.Context, *DevicePresenceRequest) (*DevicePresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DevicePresence not implemented")
+}
+func (UnimplementedPushyServiceServer) NotificationStatus(context.Context, *NotificationStatusRequest) (*NotificationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NotificationStatus not implemented")
+}
+func (UnimplementedPushyServiceServer) DeleteNotification(context.Context, *DeleteNotificationRequest) (*SimpleSuccess, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNotification not implemented")
+}
+func (UnimplementedPushyServiceServer) SubscribeToTopic(context.Context, *TopicSubscriptionRequest) (*SimpleSuccess, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubscribeToTopic not implemented")
+}
+func (UnimplementedPushyServiceServer) UnsubscribeFromTopic(context.Context, *TopicSubscriptionRequest) (*SimpleSuccess, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnsubscribeFromTopic not implemented")
+}
+func (UnimplementedPushyServiceServer) Topics(context.Context, *TopicsRequest) (*TopicsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Topics not implemented")
+}
+func (UnimplementedPushyServiceServer) NotifyDevice(context.Context, *SendNotificationRequest) (*NotificationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NotifyDevice not implemented")
+}
+func (UnimplementedPushyServiceServer) NotifyDevices(context.Context, *BatchSendRequest) (*BatchSendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NotifyDevices not implemented")
+}
+func (UnimplementedPushyServiceServer) mustEmbedUnimplementedPushyServiceServer() {}
+func (UnimplementedPushyServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafePushyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PushyServiceServer will
+// result in compilation errors.
+type UnsafePushyServiceServer interface {
+	mustEmbedUnimplementedPushyServiceServer()
+}
+
+func RegisterPushyServiceServer(s grpc.ServiceRegistrar, srv PushyServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPushyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PushyService_ServiceDesc, srv)
+}
+
+func _PushyService_DeviceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).DeviceInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_DeviceInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).DeviceInfo(ctx, req.(*DeviceInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_DevicePresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DevicePresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).DevicePresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_DevicePresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).DevicePresence(ctx, req.(*DevicePresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_NotificationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).NotificationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_NotificationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).NotificationStatus(ctx, req.(*NotificationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_DeleteNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).DeleteNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_DeleteNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).DeleteNotification(ctx, req.(*DeleteNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_SubscribeToTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopicSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).SubscribeToTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_SubscribeToTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).SubscribeToTopic(ctx, req.(*TopicSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_UnsubscribeFromTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopicSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).UnsubscribeFromTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_UnsubscribeFromTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).UnsubscribeFromTopic(ctx, req.(*TopicSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_Topics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopicsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).Topics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_Topics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).Topics(ctx, req.(*TopicsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_NotifyDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).NotifyDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_NotifyDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).NotifyDevice(ctx, req.(*SendNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushyService_NotifyDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushyServiceServer).NotifyDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushyService_NotifyDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushyServiceServer).NotifyDevices(ctx, req.(*BatchSendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PushyService_ServiceDesc is the grpc.ServiceDesc for PushyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PushyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pushy.v1.PushyService",
+	HandlerType: (*PushyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DeviceInfo",
+			Handler:    _PushyService_DeviceInfo_Handler,
+		},
+		{
+			MethodName: "DevicePresence",
+			Handler:    _PushyService_DevicePresence_Handler,
+		},
+		{
+			MethodName: "NotificationStatus",
+			Handler:    _PushyService_NotificationStatus_Handler,
+		},
+		{
+			MethodName: "DeleteNotification",
+			Handler:    _PushyService_DeleteNotification_Handler,
+		},
+		{
+			MethodName: "SubscribeToTopic",
+			Handler:    _PushyService_SubscribeToTopic_Handler,
+		},
+		{
+			MethodName: "UnsubscribeFromTopic",
+			Handler:    _PushyService_UnsubscribeFromTopic_Handler,
+		},
+		{
+			MethodName: "Topics",
+			Handler:    _PushyService_Topics_Handler,
+		},
+		{
+			MethodName: "NotifyDevice",
+			Handler:    _PushyService_NotifyDevice_Handler,
+		},
+		{
+			MethodName: "NotifyDevices",
+			Handler:    _PushyService_NotifyDevices_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pushy.proto",
+}
@@ -0,0 +1,249 @@
+// Package pushygrpc exposes pushy over gRPC, Server relays calls to a pushy.IPushyClientWithOptions
+// and Client talks to such a server while implementing pushy.IPushyClientWithOptions itself,
+// so services can switch between talking to pushy directly and through the relay without code changes.
+//  pushypb.RegisterPushyServiceServer(grpcServer, pushygrpc.NewServer(sdk))
+//  var client pushy.IPushyClientWithOptions = pushygrpc.NewClient(conn, 10*time.Second)
+package pushygrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/pushygrpc/pushypb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements pushypb.PushyServiceServer by delegating to a pushy.IPushyClientWithOptions
+type Server struct {
+	pushypb.UnimplementedPushyServiceServer
+	client pushy.IPushyClientWithOptions
+}
+
+// NewServer creates a Server sending requests using client
+func NewServer(client pushy.IPushyClientWithOptions) *Server {
+	return &Server{client: client}
+}
+
+// toStatus turns pushy results into a grpc status, requests rejected by pushy carry pushypb.PushyError as detail.
+// pushy rejecting the server's own api key is Internal, as it's nothing the caller can fix
+func toStatus(pushyErr *pushy.Error, err error) error {
+	code := codes.Unavailable
+	var statusErr pushy.StatusError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.As(err, &statusErr):
+		code = statusCode(statusErr.Code)
+	case pushyErr != nil:
+		code = codes.FailedPrecondition
+	}
+	if pushyErr == nil {
+		return status.Error(code, err.Error())
+	}
+	message := pushyErr.Error
+	if err != nil {
+		message = err.Error()
+	}
+	st, detailErr := status.New(code, message).WithDetails(&pushypb.PushyError{Error: pushyErr.Error})
+	if detailErr != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+// statusCode maps http status pushy responded with to a grpc code
+func statusCode(httpStatus int) codes.Code {
+	switch {
+	case httpStatus == http.StatusBadRequest || httpStatus == http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case httpStatus == http.StatusNotFound:
+		return codes.NotFound
+	case httpStatus == http.StatusUnauthorized || httpStatus == http.StatusForbidden:
+		return codes.Internal
+	case httpStatus == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case httpStatus >= 500:
+		return codes.Unavailable
+	}
+	return codes.FailedPrecondition
+}
+
+// idempotencyKey is the metadata key Client sends pushy.WithIdempotencyKey in
+const idempotencyKey = "idempotency-key"
+
+// deadline passes deadline of ctx on to pushy
+func deadline(ctx context.Context) []pushy.CallOption {
+	if deadline, ok := ctx.Deadline(); ok {
+		return []pushy.CallOption{pushy.WithTimeout(time.Until(deadline))}
+	}
+	return nil
+}
+
+// callOptions passes deadline of ctx and idempotency key sent by Client on to pushy
+func callOptions(ctx context.Context) []pushy.CallOption {
+	opts := deadline(ctx)
+	if keys := metadata.ValueFromIncomingContext(ctx, idempotencyKey); len(keys) > 0 {
+		opts = append(opts, pushy.WithIdempotencyKey(keys[0]))
+	}
+	return opts
+}
+
+func required(value string, name string) error {
+	if value == "" {
+		return status.Errorf(codes.InvalidArgument, "%s is required", name)
+	}
+	return nil
+}
+
+// DeviceInfo returns information about a device
+func (s *Server) DeviceInfo(ctx context.Context, req *pushypb.DeviceInfoRequest) (*pushypb.DeviceInfoResponse, error) {
+	if err := required(req.GetDeviceId(), "device_id"); err != nil {
+		return nil, err
+	}
+	info, pushyErr, err := s.client.DeviceInfoWithOptions(req.GetDeviceId(), callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	res, err := toDeviceInfo(info)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return res, nil
+}
+
+// DevicePresence returns presence of devices
+func (s *Server) DevicePresence(ctx context.Context, req *pushypb.DevicePresenceRequest) (*pushypb.DevicePresenceResponse, error) {
+	if len(req.GetDeviceIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "device_ids is required")
+	}
+	presence, pushyErr, err := s.client.DevicePresenceWithOptions(req.GetDeviceIds(), callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	return toDevicePresence(presence), nil
+}
+
+// NotificationStatus returns status of a notification
+func (s *Server) NotificationStatus(ctx context.Context, req *pushypb.NotificationStatusRequest) (*pushypb.NotificationStatusResponse, error) {
+	if err := required(req.GetPushId(), "push_id"); err != nil {
+		return nil, err
+	}
+	notificationStatus, pushyErr, err := s.client.NotificationStatusWithOptions(req.GetPushId(), callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	res, err := toNotificationStatus(notificationStatus)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return res, nil
+}
+
+// DeleteNotification deletes a pending notification
+func (s *Server) DeleteNotification(ctx context.Context, req *pushypb.DeleteNotificationRequest) (*pushypb.SimpleSuccess, error) {
+	if err := required(req.GetPushId(), "push_id"); err != nil {
+		return nil, err
+	}
+	success, pushyErr, err := s.client.DeleteNotificationWithOptions(req.GetPushId(), callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	return &pushypb.SimpleSuccess{Success: success.Success}, nil
+}
+
+// SubscribeToTopic subscribes a device to topics
+func (s *Server) SubscribeToTopic(ctx context.Context, req *pushypb.TopicSubscriptionRequest) (*pushypb.SimpleSuccess, error) {
+	if err := validateSubscription(req); err != nil {
+		return nil, err
+	}
+	success, pushyErr, err := s.client.SubscribeToTopicWithOptions(req.GetDeviceId(), req.GetTopics(), callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	return &pushypb.SimpleSuccess{Success: success.Success}, nil
+}
+
+// UnsubscribeFromTopic unsubscribes a device from topics
+func (s *Server) UnsubscribeFromTopic(ctx context.Context, req *pushypb.TopicSubscriptionRequest) (*pushypb.SimpleSuccess, error) {
+	if err := validateSubscription(req); err != nil {
+		return nil, err
+	}
+	success, pushyErr, err := s.client.UnsubscribeFromTopicWithOptions(req.GetDeviceId(), req.GetTopics(), callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	return &pushypb.SimpleSuccess{Success: success.Success}, nil
+}
+
+func validateSubscription(req *pushypb.TopicSubscriptionRequest) error {
+	if err := required(req.GetDeviceId(), "device_id"); err != nil {
+		return err
+	}
+	if len(req.GetTopics()) == 0 {
+		return status.Error(codes.InvalidArgument, "topics is required")
+	}
+	return nil
+}
+
+// Topics lists topics
+func (s *Server) Topics(ctx context.Context, req *pushypb.TopicsRequest) (*pushypb.TopicsResponse, error) {
+	lister, ok := s.client.(pushy.ITopicsClient)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "listing topics isn't supported by this server")
+	}
+	topics, pushyErr, err := lister.Topics(callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	return toTopics(topics), nil
+}
+
+// NotifyDevice validates and sends a notification
+func (s *Server) NotifyDevice(ctx context.Context, req *pushypb.SendNotificationRequest) (*pushypb.NotificationResponse, error) {
+	request := fromSendRequest(req)
+	if err := request.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res, pushyErr, err := s.client.NotifyDeviceWithOptions(request, callOptions(ctx)...)
+	if pushyErr != nil || err != nil {
+		return nil, toStatus(pushyErr, err)
+	}
+	return toNotificationResponse(res), nil
+}
+
+// NotifyDevices sends every request on its own, failures are reported per request,
+// requests left when ctx is done are reported with ctx error
+func (s *Server) NotifyDevices(ctx context.Context, req *pushypb.BatchSendRequest) (*pushypb.BatchSendResponse, error) {
+	res := &pushypb.BatchSendResponse{}
+	for _, r := range req.GetRequests() {
+		result := &pushypb.BatchSendResult{}
+		res.Results = append(res.Results, result)
+		if err := ctx.Err(); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		request := fromSendRequest(r)
+		if err := request.Validate(); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		// an idempotency key can't be shared by different requests, only the deadline applies to them
+		sent, pushyErr, err := s.client.NotifyDeviceWithOptions(request, deadline(ctx)...)
+		if pushyErr != nil {
+			result.PushyError = &pushypb.PushyError{Error: pushyErr.Error}
+		}
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Response = toNotificationResponse(sent)
+	}
+	return res, nil
+}
@@ -0,0 +1,329 @@
+package pushy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownApp is returned by clients from Registry.ForApp when app isn't registered
+var ErrUnknownApp = errors.New("pushy: unknown app")
+
+// DefaultTimeout is used for apps which don't configure a timeout
+const DefaultTimeout = 10 * time.Second
+
+// AppConfig is the configuration of a single app in a Registry
+type AppConfig struct {
+	APIToken    string
+	APIEndpoint string
+	Timeout     time.Duration
+	// RequestsPerSecond and MaxConcurrent limit requests of the app (see Limiter), 0 doesn't limit them
+	RequestsPerSecond float64
+	MaxConcurrent     int
+}
+
+// appConfigJSON is how AppConfig is stored in config files, timeout is a duration string like "10s"
+type appConfigJSON struct {
+	APIToken          string  `json:"api_token"`
+	APIEndpoint       string  `json:"api_endpoint"`
+	Timeout           string  `json:"timeout"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	MaxConcurrent     int     `json:"max_concurrent"`
+}
+
+// LoadRegistryConfig reads apps from json shaped like
+//  {"app_id": {"api_token": "...", "timeout": "5s", "requests_per_second": 50, "max_concurrent": 10}}
+func LoadRegistryConfig(r io.Reader) (map[string]AppConfig, error) {
+	var raw map[string]appConfigJSON
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	apps := make(map[string]AppConfig, len(raw))
+	for id, app := range raw {
+		config := AppConfig{
+			APIToken:          app.APIToken,
+			APIEndpoint:       app.APIEndpoint,
+			RequestsPerSecond: app.RequestsPerSecond,
+			MaxConcurrent:     app.MaxConcurrent,
+		}
+		if app.Timeout != "" {
+			timeout, err := time.ParseDuration(app.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s: timeout: %v", id, err)
+			}
+			config.Timeout = timeout
+		}
+		apps[id] = config
+	}
+	return apps, nil
+}
+
+func (c AppConfig) validate(id string) error {
+	if id == "" {
+		return errors.New("pushy: app id can't be empty")
+	}
+	if c.APIToken == "" {
+		return fmt.Errorf("pushy: app %s: api token is required", id)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("pushy: app %s: timeout can't be negative", id)
+	}
+	if c.RequestsPerSecond < 0 || c.MaxConcurrent < 0 {
+		return fmt.Errorf("pushy: app %s: limits can't be negative", id)
+	}
+	return nil
+}
+
+// NewAppClient is the default way Registry creates clients, defaults are filled for endpoint and timeout.
+// requests are limited with a Limiter when the app has limits
+func NewAppClient(config AppConfig) IPushyClientWithOptions {
+	endpoint := config.APIEndpoint
+	if endpoint == "" {
+		endpoint = GetDefaultAPIEndpoint()
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	httpClient := GetDefaultHTTPClient(timeout)
+	if config.RequestsPerSecond > 0 || config.MaxConcurrent > 0 {
+		httpClient = NewLimiter(httpClient, LimiterConfig{
+			RequestsPerSecond: config.RequestsPerSecond,
+			MaxConcurrent:     config.MaxConcurrent,
+		})
+	}
+	client := Create(config.APIToken, endpoint)
+	client.SetHTTPClient(httpClient)
+	return client
+}
+
+type registryEntry struct {
+	config AppConfig
+	client IPushyClientWithOptions
+}
+
+// Registry maps app ids to clients, useful when you've several apps each with its own secret key.
+// Reload and Rotate replace clients of apps whose config changed, so requests which are already running
+// finish with the client they started with, always get client with ForApp right before using it.
+// when only the token changed and the client has SetAPIToken (like *Pushy) the token is switched instead,
+// so the app keeps its limits and connections. it's safe to use from multiple goroutines
+type Registry struct {
+	mu        sync.RWMutex
+	apps      map[string]registryEntry
+	newClient func(AppConfig) IPushyClientWithOptions
+}
+
+// NewRegistry creates a Registry with apps, clients are created with NewAppClient
+func NewRegistry(apps map[string]AppConfig) (*Registry, error) {
+	return NewRegistryWithFactory(apps, NewAppClient)
+}
+
+// NewRegistryWithFactory creates a Registry which uses factory to create clients,
+// it can be used to wrap clients or configure http clients differently
+func NewRegistryWithFactory(apps map[string]AppConfig, factory func(AppConfig) IPushyClientWithOptions) (*Registry, error) {
+	r := &Registry{
+		apps:      map[string]registryEntry{},
+		newClient: factory,
+	}
+	if err := r.Reload(apps); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ForApp returns client for app, if app isn't registered every call of returned client fails with ErrUnknownApp
+func (r *Registry) ForApp(id string) IPushyClientWithOptions {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.apps[id]
+	if !ok {
+		return unknownApp{}
+	}
+	return entry.client
+}
+
+// Apps returns sorted ids of registered apps
+func (r *Registry) Apps() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.apps))
+	for id := range r.apps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Reload replaces registered apps with apps, clients of apps whose config didn't change are kept.
+// nothing is changed if any of the configs is invalid
+func (r *Registry) Reload(apps map[string]AppConfig) error {
+	for id, config := range apps {
+		if err := config.validate(id); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := make(map[string]registryEntry, len(apps))
+	for id, config := range apps {
+		current, ok := r.apps[id]
+		if !ok {
+			next[id] = registryEntry{config: config, client: r.newClient(config)}
+			continue
+		}
+		next[id] = r.update(current, config)
+	}
+	r.apps = next
+	return nil
+}
+
+// tokenSetter is a client whose token can be changed while it's in use, like *Pushy
+type tokenSetter interface {
+	SetAPIToken(token string)
+}
+
+// update returns entry of an app whose config changed to config, it has to be called with mu held
+func (r *Registry) update(current registryEntry, config AppConfig) registryEntry {
+	if current.config == config {
+		return current
+	}
+	sameButToken := current.config
+	sameButToken.APIToken = config.APIToken
+	if setter, ok := current.client.(tokenSetter); ok && sameButToken == config {
+		setter.SetAPIToken(config.APIToken)
+		return registryEntry{config: config, client: current.client}
+	}
+	return registryEntry{config: config, client: r.newClient(config)}
+}
+
+// Rotate switches app to a new api token, requests which already started keep using the old one.
+// the client of app is kept when it has SetAPIToken, so its Limiter isn't reset
+func (r *Registry) Rotate(id string, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.apps[id]
+	if !ok {
+		return ErrUnknownApp
+	}
+	config := entry.config
+	config.APIToken = token
+	if err := config.validate(id); err != nil {
+		return err
+	}
+	r.apps[id] = r.update(entry, config)
+	return nil
+}
+
+// WatchFile reloads registry from path (see LoadRegistryConfig) whenever its modification time changes,
+// it checks every interval until stop is closed. errors are reported to onError and the old apps stay in place
+func (r *Registry) WatchFile(path string, interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	var lastModified time.Time
+	reload := func() {
+		info, err := os.Stat(path)
+		if err != nil {
+			onError(err)
+			return
+		}
+		if info.ModTime().Equal(lastModified) {
+			return
+		}
+		lastModified = info.ModTime()
+		f, err := os.Open(path)
+		if err != nil {
+			onError(err)
+			return
+		}
+		defer f.Close()
+		apps, err := LoadRegistryConfig(f)
+		if err == nil {
+			err = r.Reload(apps)
+		}
+		if err != nil {
+			onError(fmt.Errorf("%s: %v", path, err))
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reload()
+		}
+	}
+}
+
+// unknownApp is returned from ForApp for apps which aren't registered
+type unknownApp struct{}
+
+func (unknownApp) SetHTTPClient(client IHTTPClient) {}
+
+func (unknownApp) GetHTTPClient() IHTTPClient {
+	return nil
+}
+
+func (unknownApp) DeviceInfo(deviceID string) (*DeviceInfo, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) DeviceInfoWithOptions(deviceID string, opts ...CallOption) (*DeviceInfo, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) DevicePresence(deviceID ...string) (*DevicePresenceResponse, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) DevicePresenceWithOptions(deviceIDs []string, opts ...CallOption) (*DevicePresenceResponse, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) NotificationStatus(pushID string) (*NotificationStatus, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) NotificationStatusWithOptions(pushID string, opts ...CallOption) (*NotificationStatus, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) DeleteNotification(pushID string) (*SimpleSuccess, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) DeleteNotificationWithOptions(pushID string, opts ...CallOption) (*SimpleSuccess, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) SubscribeToTopic(deviceID string, topics ...string) (*SimpleSuccess, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) SubscribeToTopicWithOptions(deviceID string, topics []string, opts ...CallOption) (*SimpleSuccess, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) UnsubscribeFromTopic(token string, topics ...string) (*SimpleSuccess, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) UnsubscribeFromTopicWithOptions(token string, topics []string, opts ...CallOption) (*SimpleSuccess, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) Topics(opts ...CallOption) (*TopicsResponse, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) NotifyDevice(request SendNotificationRequest) (*NotificationResponse, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
+
+func (unknownApp) NotifyDeviceWithOptions(request SendNotificationRequest, opts ...CallOption) (*NotificationResponse, *Error, error) {
+	return nil, nil, ErrUnknownApp
+}
@@ -0,0 +1,257 @@
+package pushy_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRegistryConfig(t *testing.T) {
+	Assert := assert.New(t)
+	apps, err := pushy.LoadRegistryConfig(strings.NewReader(`{
+		"shop": {"api_token": "SHOP", "timeout": "5s"},
+		"chat": {"api_token": "CHAT", "api_endpoint": "https://pushy.example.com", "requests_per_second": 2.5, "max_concurrent": 4}
+	}`))
+	Assert.Nil(err)
+	Assert.Equal(pushy.AppConfig{APIToken: "SHOP", Timeout: 5 * time.Second}, apps["shop"])
+	Assert.Equal(pushy.AppConfig{APIToken: "CHAT", APIEndpoint: "https://pushy.example.com", RequestsPerSecond: 2.5, MaxConcurrent: 4}, apps["chat"])
+
+	_, err = pushy.LoadRegistryConfig(strings.NewReader(`{"shop": {"api_token": "SHOP", "timeout": "soon"}}`))
+	Assert.Contains(err.Error(), "shop")
+	_, err = pushy.LoadRegistryConfig(strings.NewReader(`[]`))
+	Assert.NotNil(err)
+}
+
+func TestNewRegistry(t *testing.T) {
+	Assert := assert.New(t)
+	_, err := pushy.NewRegistry(map[string]pushy.AppConfig{"shop": {}})
+	Assert.NotNil(err)
+	_, err = pushy.NewRegistry(map[string]pushy.AppConfig{"": {APIToken: "T"}})
+	Assert.NotNil(err)
+	_, err = pushy.NewRegistry(map[string]pushy.AppConfig{"shop": {APIToken: "T", Timeout: -1}})
+	Assert.NotNil(err)
+	_, err = pushy.NewRegistry(map[string]pushy.AppConfig{"shop": {APIToken: "T", MaxConcurrent: -1}})
+	Assert.NotNil(err)
+
+	registry, err := pushy.NewRegistry(map[string]pushy.AppConfig{
+		"shop": {APIToken: "SHOP"},
+		"chat": {APIToken: "CHAT", APIEndpoint: "https://pushy.example.com"},
+	})
+	Assert.Nil(err)
+	Assert.Equal([]string{"chat", "shop"}, registry.Apps())
+	shop := registry.ForApp("shop").(*pushy.Pushy)
+	Assert.Equal("SHOP", shop.APIToken)
+	Assert.Equal(pushy.GetDefaultAPIEndpoint(), shop.APIEndpoint)
+	Assert.NotNil(shop.GetHTTPClient())
+	chat := registry.ForApp("chat").(*pushy.Pushy)
+	Assert.Equal("https://pushy.example.com", chat.APIEndpoint)
+}
+
+func TestRegistry_Limits(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	server.SetDelay(20 * time.Millisecond)
+	registry, err := pushy.NewRegistry(map[string]pushy.AppConfig{
+		"shop": {APIToken: "SHOP", APIEndpoint: server.URL, MaxConcurrent: 2},
+		"chat": {APIToken: "CHAT", APIEndpoint: server.URL},
+	})
+	Assert.Nil(err)
+	Assert.IsType(&pushy.Limiter{}, registry.ForApp("shop").GetHTTPClient())
+	Assert.IsType(&http.Client{}, registry.ForApp("chat").GetHTTPClient(), "apps without limits aren't wrapped")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.ForApp("shop").NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+		}()
+	}
+	wg.Wait()
+	Assert.Equal(6, len(server.Pushes()))
+	Assert.Equal(2, server.MaxActive())
+}
+
+func TestRegistry_RotateKeepsLimits(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	release := server.Block()
+	registry, _ := pushy.NewRegistry(map[string]pushy.AppConfig{"shop": {APIToken: "OLD", APIEndpoint: server.URL, MaxConcurrent: 1}})
+	shop := registry.ForApp("shop")
+
+	var wg sync.WaitGroup
+	send := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.ForApp("shop").NotifyDevice(pushy.SendNotificationRequest{To: []string{"D"}})
+		}()
+	}
+	send()
+	time.Sleep(20 * time.Millisecond)
+	Assert.Nil(registry.Rotate("shop", "NEW"))
+	Assert.True(shop == registry.ForApp("shop"), "only the token is switched")
+	send()
+	time.Sleep(20 * time.Millisecond)
+	Assert.Len(server.Requests(), 1, "request with the new token waits for the slot of the old one")
+	release()
+	wg.Wait()
+	requests := server.Requests()
+	Assert.Equal("OLD", requests[0].APIKey)
+	Assert.Equal("NEW", requests[1].APIKey)
+	Assert.Equal(1, server.MaxActive())
+
+	// reloading a new token keeps the client too, other changes replace it
+	Assert.Nil(registry.Reload(map[string]pushy.AppConfig{"shop": {APIToken: "NEWER", APIEndpoint: server.URL, MaxConcurrent: 1}}))
+	Assert.True(shop == registry.ForApp("shop"))
+	Assert.Nil(registry.Reload(map[string]pushy.AppConfig{"shop": {APIToken: "NEWER", APIEndpoint: server.URL, MaxConcurrent: 2}}))
+	Assert.False(shop == registry.ForApp("shop"))
+}
+
+func TestRegistry_UnknownApp(t *testing.T) {
+	Assert := assert.New(t)
+	registry, _ := pushy.NewRegistry(nil)
+	client := registry.ForApp("missing")
+	client.SetHTTPClient(pushy.GetDefaultHTTPClient(time.Second))
+	Assert.Nil(client.GetHTTPClient())
+	calls := []func() error{
+		func() error { _, _, err := client.DeviceInfo("D"); return err },
+		func() error { _, _, err := client.DevicePresence("D"); return err },
+		func() error { _, _, err := client.NotificationStatus("P"); return err },
+		func() error { _, _, err := client.DeleteNotification("P"); return err },
+		func() error { _, _, err := client.SubscribeToTopic("D", "t"); return err },
+		func() error { _, _, err := client.UnsubscribeFromTopic("D", "t"); return err },
+		func() error { _, _, err := client.(pushy.ITopicsClient).Topics(); return err },
+		func() error { _, _, err := client.NotifyDevice(pushy.SendNotificationRequest{}); return err },
+	}
+	for _, call := range calls {
+		Assert.Equal(pushy.ErrUnknownApp, call())
+	}
+	Assert.Equal(pushy.ErrUnknownApp, registry.Rotate("missing", "T"))
+}
+
+func TestRegistry_Reload(t *testing.T) {
+	Assert := assert.New(t)
+	created := 0
+	factory := func(config pushy.AppConfig) pushy.IPushyClientWithOptions {
+		created++
+		return pushy.Create(config.APIToken, config.APIEndpoint)
+	}
+	registry, err := pushy.NewRegistryWithFactory(map[string]pushy.AppConfig{
+		"shop": {APIToken: "SHOP"},
+		"chat": {APIToken: "CHAT"},
+	}, factory)
+	Assert.Nil(err)
+	shop := registry.ForApp("shop")
+
+	Assert.Nil(registry.Reload(map[string]pushy.AppConfig{
+		"shop": {APIToken: "SHOP"},
+		"news": {APIToken: "NEWS"},
+	}))
+	Assert.Equal(3, created)
+	Assert.True(shop == registry.ForApp("shop"), "unchanged apps keep their client")
+	Assert.Equal([]string{"news", "shop"}, registry.Apps())
+
+	Assert.NotNil(registry.Reload(map[string]pushy.AppConfig{"shop": {}}))
+	Assert.Equal([]string{"news", "shop"}, registry.Apps(), "invalid config is not applied")
+}
+
+func TestRegistry_RotateKeepsInFlightRequests(t *testing.T) {
+	Assert := assert.New(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens = append(tokens, r.URL.Query().Get("api_key"))
+		mu.Unlock()
+		if r.URL.Path == "/topics" {
+			close(started)
+			<-release
+		}
+		w.Write([]byte(`{"topics":[],"success":true}`))
+	}))
+	defer server.Close()
+	registry, _ := pushy.NewRegistry(map[string]pushy.AppConfig{"shop": {APIToken: "OLD", APIEndpoint: server.URL}})
+
+	done := make(chan error)
+	go func() {
+		_, _, err := registry.ForApp("shop").(pushy.ITopicsClient).Topics()
+		done <- err
+	}()
+	<-started
+	Assert.Nil(registry.Rotate("shop", "NEW"))
+	Assert.NotNil(registry.Rotate("shop", ""))
+	close(release)
+	Assert.Nil(<-done)
+
+	_, _, err := registry.ForApp("shop").DeleteNotification("PUSH")
+	Assert.Nil(err)
+	Assert.Equal([]string{"OLD", "NEW"}, tokens)
+}
+
+func TestRegistry_WatchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "apps.json")
+	ioutil.WriteFile(path, []byte(`{"shop": {"api_token": "SHOP"}}`), 0600)
+
+	registry, _ := pushy.NewRegistry(nil)
+	stop := make(chan struct{})
+	errs := make(chan error, 10)
+	go registry.WatchFile(path, time.Millisecond, stop, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	defer close(stop)
+
+	waitFor(t, func() bool { return len(registry.Apps()) == 1 })
+
+	ioutil.WriteFile(path, []byte(`{"shop": {}}`), 0600)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "api token")
+	case <-time.After(time.Second):
+		t.Fatal("invalid config should be reported")
+	}
+	assert.Equal(t, []string{"shop"}, registry.Apps())
+
+	os.Remove(path)
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	case <-time.After(time.Second):
+		t.Fatal("missing file should be reported")
+	}
+}
+
+// waitFor polls condition for up to a second
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition wasn't met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
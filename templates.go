@@ -0,0 +1,336 @@
+package pushy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// NotificationTemplate is a notification whose fields are text/template templates,
+// empty fields keep the value of the request the template is rendered onto
+//  pushy.NotificationTemplate{
+//  	Title:    "{{.Name}} replied",
+//  	Body:     "{{.Name}}: {{.Comment}}",
+//  	Data:     `{"comment_id": {{json .CommentID}}}`,
+//  	Required: []string{"Name", "Comment", "CommentID"},
+//  }
+// templates can use json to encode a value into Data safely
+type NotificationTemplate struct {
+	// Title, Body, Sound and Category are rendered into IOSNotification
+	Title    string
+	Body     string
+	Sound    string
+	Category string
+	// Data is rendered into data payload, it has to render valid json
+	Data string
+	// Required are the variables the template uses, every one has to be given when rendering
+	Required []string
+}
+
+// TemplateVars are variables a template is rendered with
+type TemplateVars map[string]interface{}
+
+// TemplateGroup are recipients which get a template rendered with the same variables
+type TemplateGroup struct {
+	To   []string
+	Vars TemplateVars
+}
+
+// templateFuncs are functions templates can use besides the builtin ones
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// compiledTemplate is a registered NotificationTemplate
+type compiledTemplate struct {
+	name     string
+	fields   map[string]*template.Template
+	required []string
+}
+
+// Templates is a set of named notification templates, it's safe to use from multiple goroutines
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]*compiledTemplate
+}
+
+// NewTemplates creates an empty set of templates
+func NewTemplates() *Templates {
+	return &Templates{templates: map[string]*compiledTemplate{}}
+}
+
+// Register parses notification and adds it as name, registering a name again replaces the template.
+// it fails when a field doesn't parse or uses a variable which isn't in Required
+func (t *Templates) Register(name string, notification NotificationTemplate) error {
+	if name == "" {
+		return fmt.Errorf("pushy: template name can't be empty")
+	}
+	compiled := &compiledTemplate{name: name, fields: map[string]*template.Template{}, required: notification.Required}
+	required := map[string]bool{}
+	for _, variable := range notification.Required {
+		required[variable] = true
+	}
+	fields := []struct {
+		name string
+		text string
+	}{
+		{"notification.title", notification.Title},
+		{"notification.body", notification.Body},
+		{"notification.sound", notification.Sound},
+		{"notification.category", notification.Category},
+		{"data", notification.Data},
+	}
+	for _, field := range fields {
+		if field.text == "" {
+			continue
+		}
+		parsed, err := template.New(field.name).Funcs(templateFuncs).Option("missingkey=error").Parse(field.text)
+		if err != nil {
+			return fmt.Errorf("pushy: template %s: %v", name, err)
+		}
+		for _, variable := range templateVariables(parsed) {
+			if !required[variable] {
+				return fmt.Errorf("pushy: template %s: %s uses %s which isn't required", name, field.name, variable)
+			}
+		}
+		compiled.fields[field.name] = parsed
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[name] = compiled
+	return nil
+}
+
+// Names returns names of registered templates, sorted
+func (t *Templates) Names() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := make([]string, 0, len(t.templates))
+	for name := range t.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders template name with vars onto a copy of request
+func (t *Templates) Render(name string, request SendNotificationRequest, vars TemplateVars) (SendNotificationRequest, error) {
+	compiled, err := t.get(name)
+	if err != nil {
+		return SendNotificationRequest{}, err
+	}
+	return compiled.render(request, vars)
+}
+
+// RenderGroups renders template name once for every group and returns a copy of request for each of them,
+// in the same order as groups. groups without recipients are skipped
+func (t *Templates) RenderGroups(name string, request SendNotificationRequest, groups []TemplateGroup) ([]SendNotificationRequest, error) {
+	compiled, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]SendNotificationRequest, 0, len(groups))
+	for i, group := range groups {
+		if len(group.To) == 0 {
+			continue
+		}
+		rendered, err := compiled.render(request, group.Vars)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %v", i, err)
+		}
+		rendered.To = group.To
+		requests = append(requests, rendered)
+	}
+	return requests, nil
+}
+
+// RenderEach renders template name for every recipient (device token => variables)
+// and returns a request for each of them, sorted by recipient
+func (t *Templates) RenderEach(name string, request SendNotificationRequest, recipients map[string]TemplateVars) ([]SendNotificationRequest, error) {
+	tokens := make([]string, 0, len(recipients))
+	for token := range recipients {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	compiled, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]SendNotificationRequest, 0, len(tokens))
+	for _, token := range tokens {
+		rendered, err := compiled.render(request, recipients[token])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", token, err)
+		}
+		rendered.To = []string{token}
+		requests = append(requests, rendered)
+	}
+	return requests, nil
+}
+
+// SendTemplate renders template name with vars onto request and sends it with client
+//  res, pushyErr, err := templates.SendTemplate(sdk, "reply", pushy.SendNotificationRequest{To: []string{token}}, vars)
+func (t *Templates) SendTemplate(client IPushyClientWithOptions, name string, request SendNotificationRequest, vars TemplateVars, opts ...CallOption) (*NotificationResponse, *Error, error) {
+	rendered, err := t.Render(name, request, vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.NotifyDeviceWithOptions(rendered, opts...)
+}
+
+// SendEach renders template name for every recipient (device token => variables) and sends them with SendPersonalized,
+// so recipients whose variables render the same notification share a request.
+// nothing is sent when rendering fails for any of the recipients
+func (t *Templates) SendEach(ctx context.Context, client IPushyClientWithOptions, name string, request SendNotificationRequest, recipients map[string]TemplateVars, config PersonalizedConfig, opts ...CallOption) (map[string]RecipientResult, error) {
+	rendered, err := t.RenderEach(name, request, recipients)
+	if err != nil {
+		return nil, err
+	}
+	notifications := make(map[string]SendNotificationRequest, len(rendered))
+	for _, notification := range rendered {
+		notifications[notification.To[0]] = notification
+	}
+	return SendPersonalized(ctx, client, notifications, config, opts...), nil
+}
+
+func (t *Templates) get(name string) (*compiledTemplate, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	compiled, ok := t.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("pushy: template %s isn't registered", name)
+	}
+	return compiled, nil
+}
+
+func (c *compiledTemplate) render(request SendNotificationRequest, vars TemplateVars) (SendNotificationRequest, error) {
+	for _, variable := range c.required {
+		if _, ok := vars[variable]; !ok {
+			return SendNotificationRequest{}, fmt.Errorf("pushy: template %s: variable %s is required", c.name, variable)
+		}
+	}
+	rendered := make(map[string]string, len(c.fields))
+	var out bytes.Buffer
+	for field, parsed := range c.fields {
+		out.Reset()
+		if err := parsed.Execute(&out, vars); err != nil {
+			return SendNotificationRequest{}, fmt.Errorf("pushy: template %s: %v", c.name, err)
+		}
+		rendered[field] = out.String()
+	}
+	if data, ok := rendered["data"]; ok {
+		if !json.Valid([]byte(data)) {
+			return SendNotificationRequest{}, ValidationError{Field: "data", Reason: fmt.Sprintf("template %s rendered invalid json", c.name)}
+		}
+		request.Data = data
+	}
+	notification := request.IOSNotification
+	set := func(field string, value *string) {
+		if text, ok := rendered[field]; ok {
+			*value = text
+		}
+	}
+	set("notification.title", &notification.Title)
+	set("notification.body", &notification.Body)
+	set("notification.sound", &notification.Sound)
+	set("notification.category", &notification.Category)
+	request.IOSNotification = notification
+	return request, nil
+}
+
+// templateVariables returns top level variables used by parsed, fields of dot are variables
+// unless range or with changed dot, $ refers to the variables unless it's inside a template
+// which was called with something else. templates called with template or block are walked as well
+func templateVariables(parsed *template.Template) []string {
+	var variables []string
+	visited := map[string]bool{}
+	// dot and dollar tell whether . and $ are the variables
+	var walk func(node parse.Node, dot bool, dollar bool)
+	walkPipe := func(pipe *parse.PipeNode, dot bool, dollar bool) {
+		if pipe != nil {
+			walk(pipe, dot, dollar)
+		}
+	}
+	walk = func(node parse.Node, dot bool, dollar bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, dot, dollar)
+			}
+		case *parse.ActionNode:
+			walkPipe(n.Pipe, dot, dollar)
+		case *parse.PipeNode:
+			for _, cmd := range n.Cmds {
+				walk(cmd, dot, dollar)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, dot, dollar)
+			}
+		case *parse.FieldNode:
+			if dot {
+				variables = append(variables, n.Ident[0])
+			}
+		case *parse.ChainNode:
+			walk(n.Node, dot, dollar)
+		case *parse.VariableNode:
+			if dollar && n.Ident[0] == "$" && len(n.Ident) > 1 {
+				variables = append(variables, n.Ident[1])
+			}
+		case *parse.IfNode:
+			walkPipe(n.Pipe, dot, dollar)
+			walk(n.List, dot, dollar)
+			walk(n.ElseList, dot, dollar)
+		case *parse.RangeNode:
+			walkPipe(n.Pipe, dot, dollar)
+			walk(n.List, false, dollar)
+			walk(n.ElseList, dot, dollar)
+		case *parse.WithNode:
+			walkPipe(n.Pipe, dot, dollar)
+			walk(n.List, false, dollar)
+			walk(n.ElseList, dot, dollar)
+		case *parse.TemplateNode:
+			walkPipe(n.Pipe, dot, dollar)
+			called := parsed.Lookup(n.Name)
+			if called == nil || called.Tree == nil {
+				// executing it fails
+				return
+			}
+			// inside the called template both . and $ are what it was called with
+			variablesPassed := passesVariables(n.Pipe, dot, dollar)
+			key := fmt.Sprintf("%s %v", n.Name, variablesPassed)
+			if visited[key] {
+				return
+			}
+			visited[key] = true
+			walk(called.Tree.Root, variablesPassed, variablesPassed)
+		}
+	}
+	walk(parsed.Tree.Root, true, true)
+	return variables
+}
+
+// passesVariables reports whether pipe is just . or $ referring to the variables
+func passesVariables(pipe *parse.PipeNode, dot bool, dollar bool) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.VariableNode:
+		return dollar && len(arg.Ident) == 1 && arg.Ident[0] == "$"
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package pushy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fossapps/pushy"
+	"github.com/fossapps/pushy/internal/pushytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func replyTemplates(t *testing.T) *pushy.Templates {
+	templates := pushy.NewTemplates()
+	err := templates.Register("reply", pushy.NotificationTemplate{
+		Title:    "{{.Name}} replied",
+		Body:     "{{.Name}}: {{.Comment}}{{if .Count}} (+{{.Count}} more){{end}}",
+		Data:     `{"comment_id": {{json .CommentID}}, "tags": [{{range $i, $tag := .Tags}}{{if $i}},{{end}}{{json $tag}}{{end}}]}`,
+		Required: []string{"Name", "Comment", "Count", "CommentID", "Tags"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
+
+func replyVars(name string) pushy.TemplateVars {
+	return pushy.TemplateVars{"Name": name, "Comment": `say "hi"`, "Count": 0, "CommentID": 7, "Tags": []string{"a", "b"}}
+}
+
+func TestTemplates_Render(t *testing.T) {
+	Assert := assert.New(t)
+	templates := replyTemplates(t)
+	base := pushy.SendNotificationRequest{
+		To:              []string{"D"},
+		TimeToLive:      60,
+		IOSNotification: pushy.IOSNotification{Sound: "ping.aiff", Title: "replaced"},
+	}
+
+	request, err := templates.Render("reply", base, replyVars("Jenna"))
+	Assert.Nil(err)
+	Assert.Equal("Jenna replied", request.IOSNotification.Title)
+	Assert.Equal(`Jenna: say "hi"`, request.IOSNotification.Body)
+	Assert.Equal("ping.aiff", request.IOSNotification.Sound, "fields without a template are kept")
+	Assert.Equal(`{"comment_id": 7, "tags": ["a","b"]}`, request.Data)
+	Assert.Equal(60, request.TimeToLive)
+	Assert.Equal([]string{"D"}, request.To)
+	Assert.Equal("replaced", base.IOSNotification.Title, "request is copied")
+	Assert.Nil(request.Validate())
+
+	vars := replyVars("Jenna")
+	vars["Count"] = 2
+	request, _ = templates.Render("reply", base, vars)
+	Assert.Equal(`Jenna: say "hi" (+2 more)`, request.IOSNotification.Body)
+}
+
+func TestTemplates_RenderErrors(t *testing.T) {
+	Assert := assert.New(t)
+	templates := replyTemplates(t)
+
+	_, err := templates.Render("missing", pushy.SendNotificationRequest{}, nil)
+	Assert.EqualError(err, "pushy: template missing isn't registered")
+
+	vars := replyVars("Jenna")
+	delete(vars, "Comment")
+	_, err = templates.Render("reply", pushy.SendNotificationRequest{}, vars)
+	Assert.EqualError(err, "pushy: template reply: variable Comment is required")
+
+	// data has to stay valid json
+	templates.Register("raw", pushy.NotificationTemplate{Data: `{"name": "{{.Name}}"}`, Required: []string{"Name"}})
+	_, err = templates.Render("raw", pushy.SendNotificationRequest{}, pushy.TemplateVars{"Name": `"quoted"`})
+	Assert.IsType(pushy.ValidationError{}, err)
+	Assert.Contains(err.Error(), "data")
+}
+
+func TestTemplates_Register(t *testing.T) {
+	Assert := assert.New(t)
+	templates := pushy.NewTemplates()
+
+	Assert.NotNil(templates.Register("", pushy.NotificationTemplate{Title: "hi"}))
+	err := templates.Register("broken", pushy.NotificationTemplate{Title: "{{.Name"})
+	Assert.Contains(err.Error(), "pushy: template broken")
+	err = templates.Register("typo", pushy.NotificationTemplate{Body: "{{.Nmae}}", Required: []string{"Name"}})
+	Assert.EqualError(err, "pushy: template typo: notification.body uses Nmae which isn't required")
+	err = templates.Register("root", pushy.NotificationTemplate{Body: "{{range .Tags}}{{.}} {{$.Owner}}{{end}}", Required: []string{"Tags"}})
+	Assert.EqualError(err, "pushy: template root: notification.body uses Owner which isn't required")
+
+	// fields of range and with elements aren't variables
+	Assert.Nil(templates.Register("nested", pushy.NotificationTemplate{
+		Body:     "{{with .User}}{{.Name}}{{end}}{{range .Items}}{{.Title}}{{end}}",
+		Required: []string{"User", "Items"},
+	}))
+	Assert.Nil(templates.Register("static", pushy.NotificationTemplate{Title: "Hello"}))
+	Assert.Equal([]string{"nested", "static"}, templates.Names())
+}
+
+func TestTemplates_RegisterCalledTemplates(t *testing.T) {
+	Assert := assert.New(t)
+	templates := pushy.NewTemplates()
+
+	// templates called with the variables use them too
+	err := templates.Register("block", pushy.NotificationTemplate{Body: `{{block "greeting" .}}Hi {{.Nmae}}{{end}}`, Required: []string{"Name"}})
+	Assert.EqualError(err, "pushy: template block: notification.body uses Nmae which isn't required")
+	err = templates.Register("define", pushy.NotificationTemplate{
+		Body:     `{{define "owner"}}{{$.Owner}}{{end}}{{range .Tags}}{{template "owner" $}}{{end}}`,
+		Required: []string{"Tags"},
+	})
+	Assert.EqualError(err, "pushy: template define: notification.body uses Owner which isn't required")
+	err = templates.Register("pipe", pushy.NotificationTemplate{Body: `{{block "user" .User.Name}}{{.}}{{end}}`, Required: []string{"Name"}})
+	Assert.EqualError(err, "pushy: template pipe: notification.body uses User which isn't required")
+
+	// fields of something else passed to a template aren't variables, recursion is fine
+	Assert.Nil(templates.Register("user", pushy.NotificationTemplate{
+		Body:     `{{block "name" .User}}{{.Name}}{{end}}{{define "loop"}}{{if .Items}}{{template "loop" .}}{{end}}{{end}}{{template "loop" .}}`,
+		Required: []string{"User", "Items"},
+	}))
+	request, err := templates.Render("user", pushy.SendNotificationRequest{}, pushy.TemplateVars{"User": map[string]string{"Name": "Jenna"}, "Items": nil})
+	Assert.Nil(err)
+	Assert.Equal("Jenna", request.IOSNotification.Body)
+}
+
+func TestTemplates_RenderGroups(t *testing.T) {
+	Assert := assert.New(t)
+	templates := replyTemplates(t)
+
+	requests, err := templates.RenderGroups("reply", pushy.SendNotificationRequest{TimeToLive: 60}, []pushy.TemplateGroup{
+		{To: []string{"A", "B"}, Vars: replyVars("Jenna")},
+		{To: nil, Vars: replyVars("Nobody")},
+		{To: []string{"C"}, Vars: replyVars("Kim")},
+	})
+	Assert.Nil(err)
+	Assert.Len(requests, 2)
+	Assert.Equal([]string{"A", "B"}, requests[0].To)
+	Assert.Equal("Jenna replied", requests[0].IOSNotification.Title)
+	Assert.Equal([]string{"C"}, requests[1].To)
+	Assert.Equal("Kim replied", requests[1].IOSNotification.Title)
+	Assert.Equal(60, requests[1].TimeToLive)
+
+	_, err = templates.RenderGroups("reply", pushy.SendNotificationRequest{}, []pushy.TemplateGroup{{To: []string{"A"}}})
+	Assert.EqualError(err, "group 0: pushy: template reply: variable Name is required")
+}
+
+func TestTemplates_RenderEach(t *testing.T) {
+	Assert := assert.New(t)
+	templates := replyTemplates(t)
+
+	requests, err := templates.RenderEach("reply", pushy.SendNotificationRequest{}, map[string]pushy.TemplateVars{
+		"B": replyVars("Kim"),
+		"A": replyVars("Jenna"),
+	})
+	Assert.Nil(err)
+	Assert.Equal([]string{"A"}, requests[0].To)
+	Assert.Equal("Jenna replied", requests[0].IOSNotification.Title)
+	Assert.Equal([]string{"B"}, requests[1].To)
+	Assert.Equal("Kim replied", requests[1].IOSNotification.Title)
+
+	_, err = templates.RenderEach("reply", pushy.SendNotificationRequest{}, map[string]pushy.TemplateVars{"A": {}})
+	Assert.Contains(err.Error(), "A: pushy: template reply")
+}
+
+func TestTemplates_SendTemplate(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	templates := replyTemplates(t)
+
+	res, pushyErr, err := templates.SendTemplate(clientFor(server), "reply", pushy.SendNotificationRequest{To: []string{"D"}}, replyVars("Jenna"), pushy.WithIdempotencyKey("reply-7"))
+	Assert.Nil(err)
+	Assert.Nil(pushyErr)
+	Assert.Equal("PUSH1", res.ID)
+	pushes := server.Pushes()
+	Assert.Len(pushes, 1)
+	Assert.Equal("reply-7", pushes[0].Header.Get("Idempotency-Key"))
+	var sent pushy.SendNotificationRequest
+	Assert.Nil(pushes[0].Decode(&sent))
+	Assert.Equal("Jenna replied", sent.IOSNotification.Title)
+
+	_, _, err = templates.SendTemplate(clientFor(server), "reply", pushy.SendNotificationRequest{To: []string{"D"}}, pushy.TemplateVars{})
+	Assert.Contains(err.Error(), "pushy: template reply")
+	Assert.Len(server.Pushes(), 1, "nothing is sent when rendering fails")
+}
+
+func TestTemplates_SendEach(t *testing.T) {
+	Assert := assert.New(t)
+	server := pushytest.NewServer()
+	defer server.Close()
+	templates := replyTemplates(t)
+
+	results, err := templates.SendEach(context.Background(), clientFor(server), "reply", pushy.SendNotificationRequest{}, map[string]pushy.TemplateVars{
+		"A": replyVars("Jenna"),
+		"B": replyVars("Kim"),
+		"C": replyVars("Kim"),
+	}, pushy.PersonalizedConfig{})
+	Assert.Nil(err)
+	Assert.Len(results, 3)
+	Assert.Equal("A", recipientsOf(server, results["A"].Response.ID))
+	Assert.Equal("B,C", recipientsOf(server, results["B"].Response.ID), "recipients with the same notification share a request")
+	Assert.Len(server.Pushes(), 2)
+
+	_, err = templates.SendEach(context.Background(), clientFor(server), "reply", pushy.SendNotificationRequest{}, map[string]pushy.TemplateVars{
+		"A": replyVars("Jenna"),
+		"B": {},
+	}, pushy.PersonalizedConfig{})
+	Assert.Contains(err.Error(), "B: pushy: template reply")
+	Assert.Len(server.Pushes(), 2, "nothing is sent when rendering fails")
+}
@@ -0,0 +1,119 @@
+package pushy
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// TransportConfig tunes connection pooling and timeouts of the http transport, zero values are replaced with
+// the values of DefaultTransportConfig. timeouts here limit single phases of a request,
+// timeout of http client limits the whole request including reading the body
+type TransportConfig struct {
+	// MaxIdleConns limits idle connections across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the number of connections kept open to pushy between requests,
+	// net/http keeps only 2 by default which makes busy senders open a new connection for almost every request
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits connections to pushy including the ones in use, 0 doesn't limit them
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept open
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval of tcp keep-alive probes
+	KeepAlive time.Duration
+	// DialTimeout limits establishing a tcp connection
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout limits tls handshake
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits waiting for response headers after request is written, 0 doesn't limit it
+	ResponseHeaderTimeout time.Duration
+	// DisableHTTP2 makes transport use HTTP/1.1 only
+	DisableHTTP2 bool
+	// TLSConfig is used for https connections
+	TLSConfig *tls.Config
+	// Proxy selects proxy for a request, defaults to http.ProxyFromEnvironment
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// DefaultTransportConfig returns configuration suited to sending lots of requests to a single host
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+		DialTimeout:         10 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		Proxy:               http.ProxyFromEnvironment,
+	}
+}
+
+// NewTransport creates a http transport with config
+func NewTransport(config TransportConfig) *http.Transport {
+	defaults := DefaultTransportConfig()
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = defaults.IdleConnTimeout
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = defaults.KeepAlive
+	}
+	if config.DialTimeout == 0 {
+		config.DialTimeout = defaults.DialTimeout
+	}
+	if config.TLSHandshakeTimeout == 0 {
+		config.TLSHandshakeTimeout = defaults.TLSHandshakeTimeout
+	}
+	if config.Proxy == nil {
+		config.Proxy = defaults.Proxy
+	}
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+	return &http.Transport{
+		Proxy:                 config.Proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       config.TLSConfig,
+		ForceAttemptHTTP2:     !config.DisableHTTP2,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// NewHTTPClient returns a http client with configured timeout using a transport created with config
+func NewHTTPClient(timeout time.Duration, config TransportConfig) IHTTPClient {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewTransport(config),
+	}
+}
+
+var (
+	tunedTransport     *http.Transport
+	tunedTransportOnce sync.Once
+)
+
+// defaultTransport is used by every client from GetDefaultHTTPClient, so they share the connection pool.
+// it doesn't go through http.DefaultTransport, tests mocking it should give the client their transport with SetHTTPClient
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	tunedTransportOnce.Do(func() {
+		tunedTransport = NewTransport(DefaultTransportConfig())
+	})
+	return tunedTransport.RoundTrip(request)
+}
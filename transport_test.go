@@ -0,0 +1,109 @@
+package pushy_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport(t *testing.T) {
+	Assert := assert.New(t)
+	transport := pushy.NewTransport(pushy.TransportConfig{
+		MaxIdleConnsPerHost:   10,
+		ResponseHeaderTimeout: time.Second,
+		DisableHTTP2:          true,
+	})
+	Assert.Equal(10, transport.MaxIdleConnsPerHost)
+	Assert.Equal(100, transport.MaxIdleConns, "zero values are replaced with defaults")
+	Assert.Equal(90*time.Second, transport.IdleConnTimeout)
+	Assert.Equal(10*time.Second, transport.TLSHandshakeTimeout)
+	Assert.Equal(time.Second, transport.ResponseHeaderTimeout)
+	Assert.False(transport.ForceAttemptHTTP2)
+	Assert.NotNil(transport.Proxy)
+	Assert.True(pushy.NewTransport(pushy.TransportConfig{}).ForceAttemptHTTP2)
+}
+
+func TestGetDefaultHTTPClient_SharesTransport(t *testing.T) {
+	Assert := assert.New(t)
+	first := pushy.GetDefaultHTTPClient(time.Second).(*http.Client)
+	second := pushy.GetDefaultHTTPClient(time.Minute).(*http.Client)
+	Assert.True(first.Transport == second.Transport)
+	Assert.Equal(time.Minute, second.Timeout)
+}
+
+func TestTransport_ReusesConnections(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"topics":[]}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	sdk := pushy.Create("SECRET", server.URL)
+	// with MaxConnsPerHost no connection dialed while another became idle can push the pool over its size and be dropped
+	sdk.SetHTTPClient(pushy.NewHTTPClient(time.Second, pushy.TransportConfig{MaxIdleConnsPerHost: 8, MaxConnsPerHost: 8}))
+	done := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		go func() {
+			for j := 0; j < 20; j++ {
+				sdk.Topics()
+			}
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		<-done
+	}
+	assert.True(t, atomic.LoadInt32(&connections) <= 8, "connections: %d", connections)
+}
+
+// BenchmarkTransport sends requests from parallel goroutines to a local server,
+// compare "tuned" with "default" which only keeps 2 idle connections like a bare http.Client.
+// conns/op shows how often a new connection had to be opened, on a real network each one costs a tls handshake
+func BenchmarkTransport(b *testing.B) {
+	var connections int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"topics":[]}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	clients := []struct {
+		name   string
+		client pushy.IHTTPClient
+	}{
+		{"default", &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone(), Timeout: 10 * time.Second}},
+		{"tuned", pushy.NewHTTPClient(10*time.Second, pushy.DefaultTransportConfig())},
+	}
+	for _, c := range clients {
+		b.Run(c.name, func(b *testing.B) {
+			sdk := pushy.Create("SECRET", server.URL)
+			sdk.SetHTTPClient(c.client)
+			b.SetParallelism(16)
+			atomic.StoreInt64(&connections, 0)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, _, err := sdk.Topics(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(atomic.LoadInt64(&connections))/float64(b.N), "conns/op")
+		})
+	}
+}
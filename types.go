@@ -1,8 +1,13 @@
 package pushy
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 )
 
 // IPushyClient interface to implement to qualify as a Pushy Client
@@ -18,12 +23,47 @@ type IPushyClient interface {
 	NotifyDevice(request SendNotificationRequest) (*NotificationResponse, *Error, error)
 }
 
+// IPushyClientWithOptions is implemented by clients which take CallOptions, every operation of IPushyClient
+// has a WithOptions variant. it's separate so existing implementations of IPushyClient keep satisfying it
+type IPushyClientWithOptions interface {
+	IPushyClient
+	DeviceInfoWithOptions(deviceID string, opts ...CallOption) (*DeviceInfo, *Error, error)
+	DevicePresenceWithOptions(deviceIDs []string, opts ...CallOption) (*DevicePresenceResponse, *Error, error)
+	NotificationStatusWithOptions(pushID string, opts ...CallOption) (*NotificationStatus, *Error, error)
+	DeleteNotificationWithOptions(pushID string, opts ...CallOption) (*SimpleSuccess, *Error, error)
+	SubscribeToTopicWithOptions(deviceID string, topics []string, opts ...CallOption) (*SimpleSuccess, *Error, error)
+	UnsubscribeFromTopicWithOptions(token string, topics []string, opts ...CallOption) (*SimpleSuccess, *Error, error)
+	NotifyDeviceWithOptions(request SendNotificationRequest, opts ...CallOption) (*NotificationResponse, *Error, error)
+}
+
+// ITopicsClient is implemented by clients which can list topics, it's kept out of IPushyClient
+// so existing implementations of IPushyClient don't have to provide it
+type ITopicsClient interface {
+	Topics(opts ...CallOption) (*TopicsResponse, *Error, error)
+}
+
 // Pushy is a basic struct with two configs: APIToken and APIEndpoint
-// implements IPushyClient interface
+// implements IPushyClient, IPushyClientWithOptions and ITopicsClient interfaces.
+// a single *Pushy can be shared between goroutines, every request reads its settings once when it starts.
+// assign fields only before sharing the client, afterwards use the setters which are synchronized.
+// Pushy must not be copied after first use
 type Pushy struct {
 	APIToken    string
 	APIEndpoint string
+	mu          sync.RWMutex
 	httpClient  IHTTPClient
+	credentials CredentialProvider
+	codec       Codec
+	// streamingThreshold is the number of recipients from which NotifyDevice streams requests
+	streamingThreshold int
+	// compressionThreshold is the size from which request bodies are compressed
+	compressionThreshold int
+	// gzipRejected is set to 1 once endpoint rejects a compressed request
+	gzipRejected int32
+	// truncate shortens notifications which are too large, nil rejects them
+	truncate TruncateStrategy
+	// metaHeaders are the names ResponseMeta is read from, nil means DefaultMetaHeaders
+	metaHeaders *MetaHeaders
 }
 
 // Error are simple error responses returned from pushy if request isn't valid
@@ -31,31 +71,95 @@ type Error struct {
 	Error string `json:"error"`
 }
 
+// Platform is the platform a device was registered from, as reported by pushy
+// values which aren't listed as constants are kept as is, use IsKnown to check for them
+type Platform string
+
+// platforms known to pushy
+const (
+	PlatformAndroid  Platform = "android"
+	PlatformIOS      Platform = "ios"
+	PlatformWeb      Platform = "web"
+	PlatformElectron Platform = "electron"
+	PlatformMacOS    Platform = "macos"
+)
+
+// IsKnown returns false if pushy returned a platform this SDK doesn't know about (yet)
+func (p Platform) IsKnown() bool {
+	switch p {
+	case PlatformAndroid, PlatformIOS, PlatformWeb, PlatformElectron, PlatformMacOS:
+		return true
+	}
+	return false
+}
+
+// UnixTime is a time.Time which is represented as unix seconds on the wire
+// 0 is treated as zero time, so that it survives a round trip
+type UnixTime struct {
+	time.Time
+}
+
+// MarshalJSON encodes time as unix seconds
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("0"), nil
+	}
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
+
+// UnmarshalJSON decodes unix seconds into time, null is ignored
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var seconds json.Number
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	value, err := seconds.Int64()
+	if err != nil {
+		return err
+	}
+	if value == 0 {
+		t.Time = time.Time{}
+		return nil
+	}
+	t.Time = time.Unix(value, 0)
+	return nil
+}
+
 // Device is basic representation of a device
 type Device struct {
-	Date     int    `json:"date"`
-	Platform string `json:"platform"`
+	Date     UnixTime `json:"date"`
+	Platform Platform `json:"platform"`
 }
 
 // DeviceInfo is a basic structure which has additional info
 type DeviceInfo struct {
-	Device        Device   `json:"device"`
-	Subscriptions []string `json:"subscriptions"`
-	Presence      struct {
-		Online     bool `json:"online"`
-		LastActive struct {
-			Date       int `json:"date"`
-			SecondsAgo int `json:"seconds_ago"`
-		} `json:"last_active"`
-	} `json:"presence"`
-	PendingNotifications []Notification `json:"pending_notifications"`
+	Device               Device             `json:"device"`
+	Subscriptions        []string           `json:"subscriptions"`
+	Presence             DevicePresenceInfo `json:"presence"`
+	PendingNotifications []Notification     `json:"pending_notifications"`
+}
+
+// DevicePresenceInfo is presence of a device as returned with DeviceInfo
+type DevicePresenceInfo struct {
+	Online     bool       `json:"online"`
+	LastActive LastActive `json:"last_active"`
+}
+
+// LastActive is when a device was last connected to pushy
+type LastActive struct {
+	Date       UnixTime `json:"date"`
+	SecondsAgo int      `json:"seconds_ago"`
 }
 
 // Notification is a basic representation of a notification
 type Notification struct {
-	ID      string      `json:"id"`
-	Date    int         `json:"date"`
-	Payload interface{} `json:"payload"`
+	ID         string      `json:"id"`
+	Date       UnixTime    `json:"date"`
+	Payload    interface{} `json:"payload"`
+	Expiration UnixTime    `json:"expiration"`
 }
 
 // DevicePresenceResponse is representation of device presence response from pushy
@@ -65,19 +169,22 @@ type DevicePresenceResponse struct {
 
 // Presence is a basic representation of a device's presence
 type Presence struct {
-	ID         string `json:"id"`
-	Online     bool   `json:"online"`
-	LastActive int    `json:"last_active"`
+	ID         string   `json:"id"`
+	Online     bool     `json:"online"`
+	LastActive UnixTime `json:"last_active"`
 }
 
 // NotificationStatus is a basic status info of a Notification
 type NotificationStatus struct {
-	Push struct {
-		Date           int         `json:"date"`
-		Payload        interface{} `json:"payload"`
-		Expiration     int         `json:"expiration"`
-		PendingDevices []string    `json:"pending_devices"`
-	} `json:"push"`
+	Push PushStatus `json:"push"`
+}
+
+// PushStatus is the state of a single push, PendingDevices are the devices which haven't received it yet
+type PushStatus struct {
+	Date           UnixTime    `json:"date"`
+	Payload        interface{} `json:"payload"`
+	Expiration     UnixTime    `json:"expiration"`
+	PendingDevices []string    `json:"pending_devices"`
 }
 
 // SimpleSuccess is a response from pushy when our request is accepted
@@ -91,6 +198,17 @@ type DeviceSubscriptionRequest struct {
 	Topics []string `json:"topics"`
 }
 
+// TopicsResponse is list of topics returned from pushy
+type TopicsResponse struct {
+	Topics []Topic `json:"topics"`
+}
+
+// Topic is a topic along with number of devices subscribed to it
+type Topic struct {
+	Name        string `json:"name"`
+	Subscribers int    `json:"subscribers"`
+}
+
 // SendNotificationRequest is representation of data to be sent to pushy service to create new notification
 type SendNotificationRequest struct {
 	To                  []string        `json:"to"`
@@ -99,6 +217,9 @@ type SendNotificationRequest struct {
 	IOSMutableContent   bool            `json:"mutable_content"`
 	IOSContentAvailable bool            `json:"content_available"`
 	IOSNotification     IOSNotification `json:"notification"`
+	// AndroidOptions are embedded as pushy takes them as top level parameters, like collapse_key,
+	// every field is left out when it's empty so options which aren't set aren't sent
+	AndroidOptions
 }
 
 // IOSNotification is a basic data for notification for iOS devices
@@ -116,10 +237,60 @@ type IOSNotification struct {
 	TitleLocArgs []string `json:"title_loc_args"`
 }
 
+// AndroidPriority is delivery priority of a notification on android devices
+type AndroidPriority string
+
+// priorities supported by android
+const (
+	AndroidPriorityNormal AndroidPriority = "normal"
+	AndroidPriorityHigh   AndroidPriority = "high"
+)
+
+// AndroidOptions controls how notification is delivered to android devices,
+// it doesn't affect what is shown, that's still up to the app using Data.
+// they're sent next to the other parameters of SendNotificationRequest, not as an object of their own
+type AndroidOptions struct {
+	Priority              AndroidPriority `json:"priority,omitempty"`
+	ChannelID             string          `json:"channel_id,omitempty"`
+	CollapseKey           string          `json:"collapse_key,omitempty"`
+	RestrictedPackageName string          `json:"restricted_package_name,omitempty"`
+	DirectBootOK          bool            `json:"direct_boot_ok,omitempty"`
+}
+
+// WebNotification is a notification shown by browsers (web push). pushy's web sdk shows
+// title, message, url and image found in data, so it's merged into Data rather than sent on its own
+type WebNotification struct {
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
+	// URL is opened when notification is clicked
+	URL string `json:"url,omitempty"`
+	// Image is shown as icon of the notification
+	Image string `json:"image,omitempty"`
+}
+
+// ValidationError is returned when a request can't be sent as is, Field is the json path of the offending value
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
 // NotificationResponse is a simple response from server when a new notification is created
 type NotificationResponse struct {
-	Success bool   `json:"success"`
-	ID      string `json:"id"`
+	Success bool              `json:"success"`
+	ID      string            `json:"id"`
+	Info    *NotificationInfo `json:"info,omitempty"`
+}
+
+// NotificationInfo is what pushy reports about recipients of a new notification
+type NotificationInfo struct {
+	// Devices is the number of devices the notification is sent to
+	Devices int `json:"devices"`
+	// Failed are the device tokens pushy doesn't know about, they won't get the notification
+	Failed []string `json:"failed,omitempty"`
 }
 
 // DevicePresenceRequest is representation of request needed to get information of device(s)'s presence
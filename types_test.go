@@ -0,0 +1,120 @@
+package pushy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fossapps/pushy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnixTime_UnmarshalJSON(t *testing.T) {
+	Assert := assert.New(t)
+	var value pushy.UnixTime
+	Assert.Nil(json.Unmarshal([]byte("1464006925"), &value))
+	Assert.Equal(time.Unix(1464006925, 0), value.Time)
+
+	var zero pushy.UnixTime
+	Assert.Nil(json.Unmarshal([]byte("0"), &zero))
+	Assert.True(zero.IsZero())
+
+	var null pushy.UnixTime
+	Assert.Nil(json.Unmarshal([]byte("null"), &null))
+	Assert.True(null.IsZero())
+
+	var invalid pushy.UnixTime
+	Assert.NotNil(json.Unmarshal([]byte(`"yesterday"`), &invalid))
+	Assert.NotNil(json.Unmarshal([]byte("1.5"), &invalid))
+}
+
+func TestUnixTime_RoundTrip(t *testing.T) {
+	Assert := assert.New(t)
+	table := []string{
+		`{"date":1445207358,"platform":"android"}`,
+		`{"date":0,"platform":"web"}`,
+		`{"date":1464008196,"platform":"something_new"}`,
+	}
+	for _, data := range table {
+		var device pushy.Device
+		Assert.Nil(json.Unmarshal([]byte(data), &device))
+		encoded, err := json.Marshal(device)
+		Assert.Nil(err)
+		Assert.JSONEq(data, string(encoded))
+	}
+}
+
+func TestPlatform_IsKnown(t *testing.T) {
+	Assert := assert.New(t)
+	for _, platform := range []pushy.Platform{pushy.PlatformAndroid, pushy.PlatformIOS, pushy.PlatformWeb, pushy.PlatformElectron, pushy.PlatformMacOS} {
+		Assert.True(platform.IsKnown(), string(platform))
+	}
+	var device pushy.Device
+	Assert.Nil(json.Unmarshal([]byte(`{"platform":"fridge"}`), &device))
+	Assert.False(device.Platform.IsKnown())
+	Assert.Equal(pushy.Platform("fridge"), device.Platform)
+}
+
+func TestResponseShapes(t *testing.T) {
+	table := []struct {
+		fixture string
+		value   interface{}
+	}{
+		{fixture: "device_info.json", value: &pushy.DeviceInfo{}},
+		{fixture: "device_presence.json", value: &pushy.DevicePresenceResponse{}},
+		{fixture: "notification_status.json", value: &pushy.NotificationStatus{}},
+		{fixture: "notification_response.json", value: &pushy.NotificationResponse{}},
+	}
+	for _, data := range table {
+		fixture, err := ioutil.ReadFile(filepath.Join("testdata", data.fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(fixture))
+		decoder.DisallowUnknownFields()
+		assert.Nil(t, decoder.Decode(data.value), data.fixture)
+		encoded, err := json.Marshal(data.value)
+		assert.Nil(t, err)
+		assert.JSONEq(t, string(fixture), string(encoded), data.fixture)
+	}
+}
+
+func TestNamedResponseTypes(t *testing.T) {
+	Assert := assert.New(t)
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", "device_info.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info pushy.DeviceInfo
+	Assert.Nil(json.Unmarshal(fixture, &info))
+	expected := pushy.DevicePresenceInfo{
+		Online: true,
+		LastActive: pushy.LastActive{
+			Date:       pushy.UnixTime{Time: time.Unix(1464006925, 0)},
+			SecondsAgo: 215,
+		},
+	}
+	Assert.Equal(expected, info.Presence)
+	Assert.Equal(int64(1466600196), info.PendingNotifications[0].Expiration.Unix())
+
+	fixture, err = ioutil.ReadFile(filepath.Join("testdata", "notification_status.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status pushy.NotificationStatus
+	Assert.Nil(json.Unmarshal(fixture, &status))
+	push := status.Push
+	Assert.Equal([]string{"fe8f7b2c102e883e5b41d2"}, push.PendingDevices)
+	Assert.Equal(int64(1466595935), push.Expiration.Unix())
+
+	fixture, err = ioutil.ReadFile(filepath.Join("testdata", "notification_response.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response pushy.NotificationResponse
+	Assert.Nil(json.Unmarshal(fixture, &response))
+	Assert.Equal(&pushy.NotificationInfo{Devices: 1, Failed: []string{"a6345d0278adc55d3474f5"}}, response.Info)
+}
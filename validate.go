@@ -0,0 +1,103 @@
+package pushy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var packageNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*(\.[a-zA-Z][a-zA-Z0-9_]*)+$`)
+
+// Validate checks request for values pushy (or the platforms it delivers to) would reject,
+// a request which is too large is a PayloadTooLargeError
+func (r SendNotificationRequest) Validate() error {
+	if err := r.validateFields(); err != nil {
+		return err
+	}
+	return SizeOf(r).check()
+}
+
+// validateFields is Validate without the size check, for callers which leave it to fitPayload
+func (r SendNotificationRequest) validateFields() error {
+	if len(r.To) == 0 {
+		return ValidationError{Field: "to", Reason: "at least one recipient is required"}
+	}
+	for i, to := range r.To {
+		if to == "" {
+			return ValidationError{Field: fmt.Sprintf("to[%d]", i), Reason: "recipient can't be empty"}
+		}
+	}
+	if r.TimeToLive < 0 {
+		return ValidationError{Field: "time_to_live", Reason: "can't be negative"}
+	}
+	if err := r.AndroidOptions.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate checks android options, priority has to be one of the AndroidPriority constants
+// and restricted package name has to be a valid java package name
+func (o AndroidOptions) Validate() error {
+	switch o.Priority {
+	case "", AndroidPriorityNormal, AndroidPriorityHigh:
+	default:
+		return ValidationError{Field: "priority", Reason: fmt.Sprintf("unknown priority %q", o.Priority)}
+	}
+	if strings.TrimSpace(o.ChannelID) != o.ChannelID {
+		return ValidationError{Field: "channel_id", Reason: "can't have leading or trailing spaces"}
+	}
+	if o.RestrictedPackageName != "" && !packageNamePattern.MatchString(o.RestrictedPackageName) {
+		return ValidationError{Field: "restricted_package_name", Reason: fmt.Sprintf("%q is not a valid package name", o.RestrictedPackageName)}
+	}
+	return nil
+}
+
+// Validate checks web specific constraints: title is required and links need to be absolute http(s) urls
+func (n WebNotification) Validate() error {
+	if n.Title == "" {
+		return ValidationError{Field: "data.title", Reason: "title is required"}
+	}
+	if err := validateURL("data.url", n.URL); err != nil {
+		return err
+	}
+	return validateURL("data.image", n.Image)
+}
+
+// MergeData adds notification to data, which has to be empty or a json object.
+// keys of notification replace the same keys of data
+func (n WebNotification) MergeData(data string) (string, error) {
+	fields := map[string]json.RawMessage{}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &fields); err != nil || fields == nil {
+			return "", ValidationError{Field: "data", Reason: "has to be a json object to add a web notification"}
+		}
+	}
+	// values of data are kept the way the app wrote them, so nothing is html escaped
+	encoded, err := marshalUnescaped(n)
+	if err != nil {
+		return "", err
+	}
+	var web map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &web); err != nil {
+		return "", err
+	}
+	for key, value := range web {
+		fields[key] = value
+	}
+	encoded, err = marshalUnescaped(fields)
+	return string(encoded), err
+}
+
+func validateURL(field string, value string) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ValidationError{Field: field, Reason: fmt.Sprintf("%q is not an absolute http(s) url", value)}
+	}
+	return nil
+}